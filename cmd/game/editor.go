@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/gdamore/tcell/v2"
+
+	"terminal-td/internal/editor"
+	mapdata "terminal-td/internal/map"
+	"terminal-td/internal/render"
+)
+
+// editorAction tells the main loop what to do after an editor key press.
+type editorAction int
+
+const (
+	editorNone editorAction = iota
+	editorClose
+	editorPlayTest
+)
+
+// mapEditor is the map editor screen: a list of maps to open, then the editor itself.
+type mapEditor struct {
+	maps      []mapdata.MapInfo
+	openIndex int
+	ed        *editor.Editor // nil while choosing a map
+	discard   bool           // ESC was pressed once with unsaved changes
+	overwrite bool           // CTRL+S was pressed once on a built-in map's id
+}
+
+func newMapEditor(maps []mapdata.MapInfo) *mapEditor {
+	return &mapEditor{maps: maps}
+}
+
+func (m *mapEditor) draw(screen tcell.Screen, blinkTimer float64) {
+	if m.ed == nil {
+		labels := []string{"+ New map"}
+		for _, info := range m.maps {
+			labels = append(labels, render.MapLabel(info))
+		}
+		render.DrawEditorOpen(screen, "MAP EDITOR", labels, m.openIndex)
+		return
+	}
+	render.DrawEditor(screen, m.ed, blinkTimer)
+}
+
+// open starts editing the selected entry: a new map or a copy of an existing one.
+func (m *mapEditor) open() {
+	if m.openIndex == 0 {
+		m.ed = editor.New()
+		log.Println("DEBUG: editor: new map")
+		return
+	}
+	info := m.maps[m.openIndex-1]
+	gm, err := mapdata.LoadMapByID(info.ID)
+	if err != nil {
+		log.Printf("ERROR: editor: load map %q: %v", info.ID, err)
+		return
+	}
+	m.ed = editor.FromMap(gm)
+	log.Printf("DEBUG: editor: editing map %q", info.ID)
+}
+
+func (m *mapEditor) handleKey(e *tcell.EventKey) editorAction {
+	if m.ed == nil {
+		return m.handleOpenKey(e)
+	}
+	ed := m.ed
+	if ed.Prompt != nil {
+		switch e.Key() {
+		case tcell.KeyEscape:
+			ed.CancelPrompt()
+		case tcell.KeyEnter:
+			ed.Submit()
+		case tcell.KeyBackspace, tcell.KeyBackspace2:
+			ed.Backspace()
+		case tcell.KeyRune:
+			ed.Type(e.Rune())
+		}
+		return editorNone
+	}
+
+	if e.Key() != tcell.KeyEscape {
+		m.discard = false
+	}
+	if e.Key() != tcell.KeyCtrlS {
+		m.overwrite = false
+	}
+	switch e.Key() {
+	case tcell.KeyEscape:
+		if ed.Dirty && !m.discard {
+			m.discard = true
+			ed.Status = "Unsaved changes: CTRL+S to save, ESC again to discard them"
+			return editorNone
+		}
+		return editorClose
+	case tcell.KeyUp:
+		ed.MoveCursor(0, -1)
+	case tcell.KeyDown:
+		ed.MoveCursor(0, 1)
+	case tcell.KeyLeft:
+		ed.MoveCursor(-1, 0)
+	case tcell.KeyRight:
+		ed.MoveCursor(1, 0)
+	case tcell.KeyTab:
+		ed.CycleBrush(1)
+	case tcell.KeyBacktab:
+		ed.CycleBrush(-1)
+	case tcell.KeyEnter:
+		ed.Paint()
+	case tcell.KeyCtrlZ:
+		ed.Undo()
+	case tcell.KeyCtrlY:
+		ed.Redo()
+	case tcell.KeyCtrlS:
+		if ed.OverridesBuiltin() && !m.overwrite {
+			m.overwrite = true
+			ed.Status = fmt.Sprintf("%q is a built-in map: CTRL+S again to replace it with yours, or I to choose a new id", ed.ID)
+			return editorNone
+		}
+		m.overwrite = false
+		if path, err := ed.Save(); err != nil {
+			ed.Status = err.Error()
+		} else {
+			ed.Status = "Saved to " + path
+		}
+	case tcell.KeyRune:
+		switch r := e.Rune(); r {
+		case 'w', 'W':
+			ed.MoveCursor(0, -1)
+		case 's', 'S':
+			ed.MoveCursor(0, 1)
+		case 'a', 'A':
+			ed.MoveCursor(-1, 0)
+		case 'd', 'D':
+			ed.MoveCursor(1, 0)
+		case ' ':
+			ed.Paint()
+		case 'x', 'X':
+			ed.Erase()
+		case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+			ed.Brush = int(r - '1')
+		case '0':
+			ed.Brush = 9
+		case 'u', 'U':
+			ed.Undo()
+		case 'r', 'R':
+			ed.Redo()
+		case '[':
+			ed.Resize(-1, 0)
+		case ']':
+			ed.Resize(1, 0)
+		case '{':
+			ed.Resize(0, -1)
+		case '}':
+			ed.Resize(0, 1)
+		case '+', '=':
+			ed.AdjustBaseHP(1)
+		case '-':
+			ed.AdjustBaseHP(-1)
+		case 'n', 'N':
+			ed.RenameSpawn()
+		case 'm', 'M':
+			ed.EditName()
+		case 'i', 'I':
+			ed.EditID()
+		case 'p', 'P':
+			if len(ed.Problems) > 0 {
+				ed.Status = "Cannot play-test: " + ed.Problems[0]
+				return editorNone
+			}
+			return editorPlayTest
+		}
+	}
+	return editorNone
+}
+
+func (m *mapEditor) handleOpenKey(e *tcell.EventKey) editorAction {
+	switch e.Key() {
+	case tcell.KeyEscape:
+		return editorClose
+	case tcell.KeyUp:
+		m.moveSelection(-1)
+	case tcell.KeyDown:
+		m.moveSelection(1)
+	case tcell.KeyEnter:
+		m.open()
+	case tcell.KeyRune:
+		switch e.Rune() {
+		case 'w', 'W':
+			m.moveSelection(-1)
+		case 's', 'S':
+			m.moveSelection(1)
+		case ' ':
+			m.open()
+		}
+	}
+	return editorNone
+}
+
+// moveSelection moves through "New map" and the existing maps, stopping at either end.
+func (m *mapEditor) moveSelection(delta int) {
+	i := m.openIndex + delta
+	if i >= 0 && i <= len(m.maps) {
+		m.openIndex = i
+	}
+}
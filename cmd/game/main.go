@@ -2,6 +2,7 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
@@ -12,9 +13,11 @@ import (
 	"github.com/gdamore/tcell/v2"
 
 	"terminal-td/internal/config"
-	"terminal-td/internal/entities"
+	"terminal-td/internal/content"
+	"terminal-td/internal/difficulty"
 	"terminal-td/internal/game"
 	mapdata "terminal-td/internal/map"
+	"terminal-td/internal/mods"
 	"terminal-td/internal/render"
 	"terminal-td/internal/updater"
 )
@@ -69,7 +72,20 @@ func cleanupSessionLogs(dir string) {
 func main() {
 	justUpdated := flag.Bool("just-updated", false, "Show changelog after update")
 	changelogPath := flag.String("changelog", "", "Path to changelog file")
+	replayPath := flag.String("replay", "", "Play back a replay file")
+	contentDir := flag.String("content-dir", "", "Directory of custom maps, waves and enemies (default: content/ in the config directory)")
 	flag.Parse()
+	content.SetDir(*contentDir)
+
+	var replay *game.Replay
+	if *replayPath != "" {
+		r, err := game.LoadReplay(*replayPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "cannot play replay %s: %v\n", *replayPath, err)
+			os.Exit(1)
+		}
+		replay = r
+	}
 
 	f, err := initSessionLog()
 	if err != nil {
@@ -80,6 +96,11 @@ func main() {
 		log.Printf("=== Terminal Tower Defense %s ===", game.Version)
 		log.Println("Debug logging initialized")
 	}
+	if dir, err := content.Dir(); err != nil {
+		log.Printf("content dir: %v", err)
+	} else {
+		log.Printf("Content directory: %s", dir)
+	}
 
 	screen, err := tcell.NewScreen()
 	if err != nil {
@@ -101,6 +122,19 @@ func main() {
 		cfg = config.Default()
 	}
 
+	modsDir, err := mods.Dir()
+	if err != nil {
+		log.Printf("mods dir: %v", err)
+	}
+	var installedMods []*mods.Mod
+	scanMods := func() {
+		if modsDir != "" {
+			installedMods = mods.Scan(modsDir, game.Version, cfg.DisabledMods)
+		}
+		content.SetMods(mods.Sources(installedMods))
+	}
+	scanMods()
+
 	var updateAvailable bool
 	var latestVersion string
 	var latestRelease *updater.Release
@@ -117,6 +151,15 @@ func main() {
 	}
 
 	g := game.NewGame()
+	if replay != nil {
+		rg, err := game.NewReplayGame(replay)
+		if err != nil {
+			screen.Fini()
+			fmt.Fprintf(os.Stderr, "cannot play replay %s: %v\n", *replayPath, err)
+			os.Exit(1)
+		}
+		g = rg
+	}
 	log.Println("Game instance created")
 
 	events := make(chan tcell.Event, 10)
@@ -128,7 +171,22 @@ func main() {
 	defer ticker.Stop()
 
 	running := true
-	menuSelection := render.MenuStart
+	menuIndex := 0
+	hasSave := game.SaveExists()
+	resumedRun := false // the current run was loaded from the save file
+	quitReturnState := game.StateInWave
+	replayWritten := false
+
+	// writeReplay saves the recorded replay once per run.
+	writeReplay := func() {
+		if g.Recording == nil || replayWritten {
+			return
+		}
+		replayWritten = true
+		if _, err := g.WriteReplay(); err != nil {
+			log.Printf("ERROR: Failed to write replay: %v", err)
+		}
+	}
 	showControls := false
 	showSettings := false
 	showChangelog := false
@@ -137,14 +195,127 @@ func main() {
 	showUpdateScreen := false
 	var updateProgress *updater.Progress
 	var updateStarted bool
+	showMods := false
+	modsIndex := 0
+	var mapEd *mapEditor // the map editor, while it is open
+	var waveEd *waveEditor
+	playTesting := false // the current run is a play-test from the map editor
 	showMapSelection := false
 	var availableMaps []mapdata.MapInfo
 	var mapSelectionIndex int
-	if maps, err := mapdata.ListMaps(); err != nil {
-		log.Printf("load maps: %v", err)
-		availableMaps = []mapdata.MapInfo{{ID: "classic", Name: "Tutorial"}}
-	} else {
-		availableMaps = maps
+	loadMapList := func() {
+		if maps, err := mapdata.ListMaps(); err != nil {
+			log.Printf("load maps: %v", err)
+			availableMaps = []mapdata.MapInfo{{ID: "classic", Name: "Tutorial"}}
+		} else {
+			availableMaps = maps
+		}
+		mapSelectionIndex = 0
+	}
+	loadMapList()
+
+	// applyMods saves which mods are enabled and reloads the map list with them.
+	applyMods := func() {
+		cfg.DisabledMods = mods.Disabled(installedMods)
+		if err := config.Save(cfg); err != nil {
+			log.Printf("config save: %v", err)
+		}
+		content.SetMods(mods.Sources(installedMods))
+		loadMapList()
+	}
+
+	endlessMode := false
+	presetDB, err := difficulty.DefaultPresets()
+	if err != nil {
+		log.Printf("load difficulty presets: %v", err)
+		presetDB = &difficulty.PresetDatabase{Presets: map[string]difficulty.Preset{}}
+	}
+	presetIndex := presetDB.Index(difficulty.DefaultPresetID)
+	selectedPreset := func() *difficulty.Preset { return presetDB.At(presetIndex) }
+	cyclePreset := func(delta int) {
+		if n := len(presetDB.Order); n > 0 {
+			presetIndex = ((presetIndex+delta)%n + n) % n
+			log.Printf("DEBUG: Difficulty set to %q", presetDB.Order[presetIndex])
+		}
+	}
+
+	startSelectedMap := func() {
+		if mapSelectionIndex < 0 || mapSelectionIndex >= len(availableMaps) {
+			return
+		}
+		selectedMapID := availableMaps[mapSelectionIndex].ID
+		opts := game.Options{Endless: endlessMode, Seed: time.Now().UnixNano()}
+		if p := selectedPreset(); p != nil {
+			opts.Difficulty = p.ID
+		}
+		log.Printf("DEBUG: Starting game with map %q (difficulty=%q endless=%v seed=%d)", selectedMapID, opts.Difficulty, opts.Endless, opts.Seed)
+		m, err := mapdata.LoadMapByID(selectedMapID)
+		if err != nil {
+			log.Printf("ERROR: Failed to load map %q: %v", selectedMapID, err)
+			m, _ = mapdata.DefaultMap()
+		}
+		g = game.NewGameWithOptions(m, opts)
+		g.Begin()
+		g.StartRecording()
+		replayWritten = false
+		resumedRun = false
+		showMapSelection = false
+	}
+
+	continueSavedRun := func() {
+		s, err := game.LoadSave()
+		if err != nil {
+			log.Printf("ERROR: Failed to load save: %v", err)
+			hasSave = false
+			menuIndex = 0
+			return
+		}
+		restored, err := game.RestoreGame(s)
+		if err != nil {
+			log.Printf("ERROR: Failed to restore save: %v", err)
+			hasSave = false
+			menuIndex = 0
+			return
+		}
+		g = restored
+		resumedRun = true
+	}
+
+	// startPlayTest runs the map being edited with its waves (or the built-in fallback waves).
+	startPlayTest := func() {
+		m, err := mapEd.ed.Map()
+		if err != nil {
+			mapEd.ed.Status = "Cannot play-test: " + err.Error()
+			return
+		}
+		opts := game.Options{Seed: time.Now().UnixNano()}
+		if p := selectedPreset(); p != nil {
+			opts.Difficulty = p.ID
+		}
+		log.Printf("DEBUG: Play-testing map %q", m.ID)
+		g = game.NewGameWithOptions(m, opts)
+		g.Begin()
+		playTesting = true
+	}
+
+	// quitGame exits the program, saving the run first if one is in progress. A play-test
+	// returns to the map editor instead.
+	quitGame := func() {
+		if playTesting {
+			log.Println("DEBUG: Play-test ended")
+			playTesting = false
+			g.Manager.State = game.StateMenu
+			mapEd.ed.Status = "Play-test ended"
+			return
+		}
+		if quitReturnState != game.StateWon && quitReturnState != game.StateLost && g.CanSave() {
+			if err := g.Save(); err != nil {
+				log.Printf("ERROR: Failed to save run: %v", err)
+			}
+		}
+		writeReplay()
+		running = false
+		close(quit)
 	}
 
 	handleMenuSelect := func() bool {
@@ -162,6 +333,14 @@ func main() {
 			showControls = false
 			return false
 		}
+		if showMods {
+			if modsIndex < len(installedMods) && installedMods[modsIndex].Usable() {
+				m := installedMods[modsIndex]
+				m.Enabled = !m.Enabled
+				log.Printf("DEBUG: Mod %q enabled=%v", m.ID, m.Enabled)
+			}
+			return false
+		}
 		if showChangelog {
 			showChangelog = false
 			return false
@@ -172,19 +351,27 @@ func main() {
 			}
 			return false
 		}
-		if menuSelection == render.MenuUpdateAvailable && updateAvailable && latestRelease != nil {
+		items := render.MenuItems(hasSave, updateAvailable)
+		if menuIndex >= len(items) {
+			menuIndex = len(items) - 1
+		}
+		menuSelection := items[menuIndex]
+		if menuSelection == render.MenuUpdateAvailable && latestRelease != nil {
 			showUpdateScreen = true
 			updateProgress = &updater.Progress{}
 			updateStarted = false
 			return false
 		}
-		if menuSelection == render.MenuUpdateAvailable && !updateAvailable || menuSelection == render.MenuQuit {
+		if menuSelection == render.MenuQuit {
 			log.Println("DEBUG: Quitting from menu")
 			running = false
 			close(quit)
 			return true
 		}
 		switch menuSelection {
+		case render.MenuContinue:
+			log.Println("DEBUG: Continuing saved run")
+			continueSavedRun()
 		case render.MenuStart:
 			log.Println("DEBUG: Showing map selection")
 			showMapSelection = true
@@ -195,6 +382,17 @@ func main() {
 		case render.MenuSettings:
 			log.Println("DEBUG: Showing settings")
 			showSettings = true
+		case render.MenuEditor:
+			log.Println("DEBUG: Opening map editor")
+			mapEd = newMapEditor(availableMaps)
+		case render.MenuWaveEditor:
+			log.Println("DEBUG: Opening wave editor")
+			waveEd = newWaveEditor(availableMaps)
+		case render.MenuMods:
+			log.Println("DEBUG: Showing mods")
+			scanMods()
+			showMods = true
+			modsIndex = 0
 		case render.MenuChangelog:
 			release, err := updater.FetchLatest(updater.DefaultOwner, updater.DefaultRepo)
 			if err != nil {
@@ -228,26 +426,39 @@ func main() {
 						go updater.RunUpdateWithProgress(latestRelease, updateProgress)
 					}
 					render.DrawUpdateScreen(screen, updateProgress.Step, updateProgress.Percent, updateProgress.Done, updateProgress.Err)
+				} else if mapEd != nil {
+					mapEd.draw(screen, float64(time.Now().UnixMilli())/1000)
+				} else if waveEd != nil {
+					waveEd.draw(screen)
 				} else if showMapSelection {
-					render.DrawMapSelection(screen, availableMaps, mapSelectionIndex)
+					render.DrawMapSelection(screen, availableMaps, mapSelectionIndex, endlessMode, selectedPreset())
 				} else if showSettings {
 					render.DrawSettings(screen, cfg.CheckForUpdates)
+				} else if showMods {
+					render.DrawMods(screen, installedMods, mods.Conflicts(installedMods), modsIndex, modsDir)
 				} else if showControls {
 					render.DrawControls(screen)
 				} else if showChangelog {
 					render.DrawChangelog(screen, changelogContent)
 				} else {
-					render.DrawMainMenu(screen, menuSelection, updateAvailable, latestVersion)
+					render.DrawMainMenu(screen, render.MenuItems(hasSave, updateAvailable), menuIndex, latestVersion)
 				}
 
 			case game.StateQuitConfirm:
 				render.DrawQuitConfirm(screen, quitConfirmYes)
 
 			default:
-				g.Manager.Update(dt)
+				g.Update(dt)
 
-				if g.Manager.IsSimulationRunning() {
-					g.Update(dt)
+				if g.Manager.State == game.StateWon || g.Manager.State == game.StateLost {
+					if resumedRun {
+						if err := game.DeleteSave(); err != nil {
+							log.Printf("delete save: %v", err)
+						}
+						resumedRun = false
+						hasSave = false
+					}
+					writeReplay()
 				}
 
 				w, h := screen.Size()
@@ -282,9 +493,9 @@ func main() {
 				}
 
 				if g.Manager.Mode == game.ModeBuild {
-					templates := game.GetTowerTemplates()
-					template := templates[entities.TowerBasic]
-					render.DrawRange(screen, g.CursorX, g.CursorY, template.Range, offsetX, offsetY)
+					if def := g.BuildTowerDef(); def != nil {
+						render.DrawRange(screen, g.CursorX, g.CursorY, def.Range+g.RangeBonusAt(g.CursorX, g.CursorY), offsetX, offsetY)
+					}
 				} else if g.Manager.Mode == game.ModeSelect {
 					tower := g.GetTowerAt(g.Manager.SelectedTowerX, g.Manager.SelectedTowerY)
 					if tower != nil {
@@ -317,6 +528,24 @@ func main() {
 			switch e := ev.(type) {
 
 			case *tcell.EventKey:
+				if mapEd != nil && g.Manager.State == game.StateMenu {
+					switch mapEd.handleKey(e) {
+					case editorClose:
+						log.Println("DEBUG: Closing map editor")
+						mapEd = nil
+						loadMapList()
+					case editorPlayTest:
+						startPlayTest()
+					}
+					continue
+				}
+				if waveEd != nil && g.Manager.State == game.StateMenu {
+					if waveEd.handleKey(e) == editorClose {
+						log.Println("DEBUG: Closing wave editor")
+						waveEd = nil
+					}
+					continue
+				}
 				switch e.Key() {
 
 				case tcell.KeyEscape:
@@ -328,6 +557,9 @@ func main() {
 							showMapSelection = false
 						} else if showSettings {
 							showSettings = false
+						} else if showMods {
+							applyMods()
+							showMods = false
 						} else if showControls {
 							log.Println("DEBUG: Exiting controls screen")
 							showControls = false
@@ -340,13 +572,14 @@ func main() {
 						}
 					} else if g.Manager.State == game.StateQuitConfirm {
 						log.Println("DEBUG: Cancel quit confirmation")
-						g.Manager.State = game.StateInWave
+						g.Manager.State = quitReturnState
 					} else if g.Manager.Mode != game.ModeNormal {
 						log.Printf("DEBUG: Exiting mode %d", g.Manager.Mode)
 						g.Manager.Mode = game.ModeNormal
 					} else {
 						log.Println("DEBUG: Showing quit confirmation")
 						quitConfirmYes = false
+						quitReturnState = g.Manager.State
 						g.Manager.State = game.StateQuitConfirm
 					}
 
@@ -357,9 +590,13 @@ func main() {
 						if mapSelectionIndex > 0 {
 							mapSelectionIndex--
 						}
-					} else if g.Manager.State == game.StateMenu && !showControls && !showSettings && !showChangelog && !showUpdateScreen && !showMapSelection {
-						if menuSelection > render.MenuStart {
-							menuSelection--
+					} else if g.Manager.State == game.StateMenu && showMods {
+						if modsIndex > 0 {
+							modsIndex--
+						}
+					} else if g.Manager.State == game.StateMenu && !showControls && !showSettings && !showMods && !showChangelog && !showUpdateScreen && !showMapSelection {
+						if menuIndex > 0 {
+							menuIndex--
 						}
 					} else if g.Manager.State != game.StateMenu && g.Manager.State != game.StateQuitConfirm {
 						g.CursorY--
@@ -373,10 +610,13 @@ func main() {
 						if mapSelectionIndex < len(availableMaps)-1 {
 							mapSelectionIndex++
 						}
-					} else if g.Manager.State == game.StateMenu && !showControls && !showSettings && !showChangelog && !showUpdateScreen && !showMapSelection {
-						maxOpt := render.MaxMenuOption(updateAvailable)
-						if menuSelection < maxOpt {
-							menuSelection++
+					} else if g.Manager.State == game.StateMenu && showMods {
+						if modsIndex < len(installedMods)-1 {
+							modsIndex++
+						}
+					} else if g.Manager.State == game.StateMenu && !showControls && !showSettings && !showMods && !showChangelog && !showUpdateScreen && !showMapSelection {
+						if menuIndex < len(render.MenuItems(hasSave, updateAvailable))-1 {
+							menuIndex++
 						}
 					} else if g.Manager.State != game.StateMenu && g.Manager.State != game.StateQuitConfirm {
 						g.CursorY++
@@ -386,6 +626,8 @@ func main() {
 				case tcell.KeyLeft:
 					if g.Manager.State == game.StateQuitConfirm {
 						quitConfirmYes = true
+					} else if g.Manager.State == game.StateMenu && showMapSelection {
+						cyclePreset(-1)
 					} else if g.Manager.State != game.StateMenu && g.Manager.State != game.StateQuitConfirm {
 						g.CursorX--
 						clampCursor(g)
@@ -394,38 +636,36 @@ func main() {
 				case tcell.KeyRight:
 					if g.Manager.State == game.StateQuitConfirm {
 						quitConfirmYes = false
+					} else if g.Manager.State == game.StateMenu && showMapSelection {
+						cyclePreset(1)
 					} else if g.Manager.State != game.StateMenu && g.Manager.State != game.StateQuitConfirm {
 						g.CursorX++
 						clampCursor(g)
 					}
 
+				case tcell.KeyTab, tcell.KeyBacktab:
+					if g.Manager.State != game.StateMenu && g.Manager.State != game.StateQuitConfirm && g.Manager.Mode == game.ModeBuild {
+						if e.Key() == tcell.KeyTab {
+							g.CycleBuildTower(1)
+						} else {
+							g.CycleBuildTower(-1)
+						}
+					}
+
 				case tcell.KeyEnter:
 					if showUpdateScreen && updateProgress != nil && updateProgress.Done && updateProgress.Err == nil {
 						os.Exit(0)
 					}
 					if g.Manager.State == game.StateQuitConfirm {
 						if quitConfirmYes {
-							running = false
-							close(quit)
+							quitGame()
 						} else {
-							g.Manager.State = game.StateInWave
+							g.Manager.State = quitReturnState
 						}
 						continue
 					}
 					if g.Manager.State == game.StateMenu && showMapSelection {
-						if mapSelectionIndex >= 0 && mapSelectionIndex < len(availableMaps) {
-							selectedMapID := availableMaps[mapSelectionIndex].ID
-							log.Printf("DEBUG: Starting game with map %q", selectedMapID)
-							m, err := mapdata.LoadMapByID(selectedMapID)
-							if err != nil {
-								log.Printf("ERROR: Failed to load map %q: %v", selectedMapID, err)
-								m, _ = mapdata.DefaultMap()
-							}
-							g = game.NewGameFromMap(m)
-							g.Manager.State = game.StatePreWave
-							g.Manager.InterWaveTimer = 5.0
-							showMapSelection = false
-						}
+						startSelectedMap()
 						continue
 					}
 					if handleMenuSelect() {
@@ -441,9 +681,13 @@ func main() {
 							if mapSelectionIndex > 0 {
 								mapSelectionIndex--
 							}
-						} else if g.Manager.State == game.StateMenu && !showControls && !showSettings && !showChangelog && !showUpdateScreen && !showMapSelection {
-							if menuSelection > render.MenuStart {
-								menuSelection--
+						} else if g.Manager.State == game.StateMenu && showMods {
+							if modsIndex > 0 {
+								modsIndex--
+							}
+						} else if g.Manager.State == game.StateMenu && !showControls && !showSettings && !showMods && !showChangelog && !showUpdateScreen && !showMapSelection {
+							if menuIndex > 0 {
+								menuIndex--
 							}
 						} else if g.Manager.State != game.StateMenu && g.Manager.State != game.StateQuitConfirm {
 							g.CursorY--
@@ -457,10 +701,13 @@ func main() {
 							if mapSelectionIndex < len(availableMaps)-1 {
 								mapSelectionIndex++
 							}
-						} else if g.Manager.State == game.StateMenu && !showControls && !showSettings && !showChangelog && !showUpdateScreen && !showMapSelection {
-							maxOpt := render.MaxMenuOption(updateAvailable)
-							if menuSelection < maxOpt {
-								menuSelection++
+						} else if g.Manager.State == game.StateMenu && showMods {
+							if modsIndex < len(installedMods)-1 {
+								modsIndex++
+							}
+						} else if g.Manager.State == game.StateMenu && !showControls && !showSettings && !showMods && !showChangelog && !showUpdateScreen && !showMapSelection {
+							if menuIndex < len(render.MenuItems(hasSave, updateAvailable))-1 {
+								menuIndex++
 							}
 						} else if g.Manager.State != game.StateMenu && g.Manager.State != game.StateQuitConfirm {
 							g.CursorY++
@@ -470,6 +717,8 @@ func main() {
 					case 'a', 'A':
 						if g.Manager.State == game.StateQuitConfirm {
 							quitConfirmYes = true
+						} else if g.Manager.State == game.StateMenu && showMapSelection {
+							cyclePreset(-1)
 						} else if g.Manager.State != game.StateMenu && g.Manager.State != game.StateQuitConfirm {
 							g.CursorX--
 							clampCursor(g)
@@ -478,6 +727,8 @@ func main() {
 					case 'd', 'D':
 						if g.Manager.State == game.StateQuitConfirm {
 							quitConfirmYes = false
+						} else if g.Manager.State == game.StateMenu && showMapSelection {
+							cyclePreset(1)
 						} else if g.Manager.State != game.StateMenu && g.Manager.State != game.StateQuitConfirm {
 							g.CursorX++
 							clampCursor(g)
@@ -485,31 +736,36 @@ func main() {
 
 					case '=', '+':
 						if g.Manager.State != game.StateMenu && g.Manager.State != game.StateQuitConfirm {
-							oldSpeed := g.Speed
-							g.Speed = min(4.0, g.Speed*2)
-							if oldSpeed != g.Speed {
+							if g.Replaying() {
+								g.Speed = min(16.0, g.Speed*2)
+							} else if g.Apply(game.Command{Type: game.CmdSetSpeed, Speed: min(4.0, g.Speed*2)}) {
 								log.Printf("DEBUG: Speed increased to %.2fx", g.Speed)
 							}
 						}
 
 					case '-':
 						if g.Manager.State != game.StateMenu && g.Manager.State != game.StateQuitConfirm {
-							oldSpeed := g.Speed
-							g.Speed = max(0.25, g.Speed/2)
-							if oldSpeed != g.Speed {
+							if g.Replaying() {
+								g.Speed = max(0.25, g.Speed/2)
+							} else if g.Apply(game.Command{Type: game.CmdSetSpeed, Speed: max(0.25, g.Speed/2)}) {
 								log.Printf("DEBUG: Speed decreased to %.2fx", g.Speed)
 							}
 						}
 
 					case 'p', 'P':
 						if g.Manager.State != game.StateMenu && g.Manager.State != game.StateQuitConfirm {
-							g.Manager.TogglePause()
+							if g.Replaying() {
+								g.Manager.TogglePause()
+							} else {
+								g.Apply(game.Command{Type: game.CmdTogglePause})
+							}
 						}
 
 					case 'r', 'R':
 						if g.Manager.State == game.StateWon || g.Manager.State == game.StateLost {
 							log.Println("DEBUG: Restarting game")
 							g.Reset()
+							replayWritten = false
 						}
 
 					case 'b', 'B':
@@ -526,31 +782,26 @@ func main() {
 					case 'y', 'Y':
 						if g.Manager.State == game.StateQuitConfirm {
 							log.Println("DEBUG: User confirmed quit")
-							running = false
-							close(quit)
+							quitGame()
 						}
 
 					case 'n', 'N':
 						if g.Manager.State == game.StateQuitConfirm {
 							log.Println("DEBUG: User cancelled quit")
-							g.Manager.State = game.StateInWave
+							g.Manager.State = quitReturnState
+						} else if g.Manager.State != game.StateMenu {
+							g.Apply(game.Command{Type: game.CmdCallWave})
+						}
+
+					case 'e', 'E':
+						if g.Manager.State == game.StateMenu && showMapSelection {
+							endlessMode = !endlessMode
+							log.Printf("DEBUG: Endless mode %v", endlessMode)
 						}
 
 					case ' ', '\n', '\r':
 						if g.Manager.State == game.StateMenu && showMapSelection {
-							if mapSelectionIndex >= 0 && mapSelectionIndex < len(availableMaps) {
-								selectedMapID := availableMaps[mapSelectionIndex].ID
-								log.Printf("DEBUG: Starting game with map %q", selectedMapID)
-								m, err := mapdata.LoadMapByID(selectedMapID)
-								if err != nil {
-									log.Printf("ERROR: Failed to load map %q: %v", selectedMapID, err)
-									m, _ = mapdata.DefaultMap()
-								}
-								g = game.NewGameFromMap(m)
-								g.Manager.State = game.StatePreWave
-								g.Manager.InterWaveTimer = 5.0
-								showMapSelection = false
-							}
+							startSelectedMap()
 							continue
 						}
 						if showUpdateScreen && updateProgress != nil && updateProgress.Done && updateProgress.Err == nil {
@@ -562,13 +813,13 @@ func main() {
 							}
 						} else if g.Manager.State == game.StateQuitConfirm {
 							if quitConfirmYes {
-								running = false
-								close(quit)
+								quitGame()
 							} else {
-								g.Manager.State = game.StateInWave
+								g.Manager.State = quitReturnState
 							}
 						} else if g.Manager.Mode == game.ModeBuild {
-							if g.PlaceTower(entities.TowerBasic) {
+							def := g.BuildTowerDef()
+							if def != nil && g.Apply(game.Command{Type: game.CmdPlaceTower, X: g.CursorX, Y: g.CursorY, Tower: def.ID}) {
 								log.Printf("DEBUG: Tower placed at (%d, %d)", g.CursorX, g.CursorY)
 								g.Manager.Mode = game.ModeNormal
 							} else {
@@ -590,7 +841,7 @@ func main() {
 								linkable := g.GetLinkableTowers(g.Manager.SelectedTowerX, g.Manager.SelectedTowerY)
 								for _, p := range linkable {
 									if p[0] == g.CursorX && p[1] == g.CursorY {
-										if g.AddWall(g.Manager.SelectedTowerX, g.Manager.SelectedTowerY, p[0], p[1]) {
+										if g.Apply(game.Command{Type: game.CmdAddWall, X: g.Manager.SelectedTowerX, Y: g.Manager.SelectedTowerY, X2: p[0], Y2: p[1]}) {
 											g.Manager.SelectingWallTarget = false
 											g.Manager.Mode = game.ModeNormal
 										}
@@ -601,7 +852,7 @@ func main() {
 								wallsFor := g.GetWallsForTower(g.Manager.SelectedTowerX, g.Manager.SelectedTowerY)
 								for _, p := range wallsFor {
 									if p[0] == g.CursorX && p[1] == g.CursorY {
-										if g.RemoveWall(g.Manager.SelectedTowerX, g.Manager.SelectedTowerY, p[0], p[1]) {
+										if g.Apply(game.Command{Type: game.CmdRemoveWall, X: g.Manager.SelectedTowerX, Y: g.Manager.SelectedTowerY, X2: p[0], Y2: p[1]}) {
 											g.Manager.SelectingWallRemoveTarget = false
 											g.Manager.Mode = game.ModeNormal
 										}
@@ -638,10 +889,22 @@ func main() {
 						}
 					case '3':
 						if g.Manager.State != game.StateMenu && g.Manager.State != game.StateQuitConfirm && g.Manager.Mode == game.ModeSelect && !g.Manager.SelectingWallTarget && !g.Manager.SelectingWallRemoveTarget {
-							if g.SellTower(g.Manager.SelectedTowerX, g.Manager.SelectedTowerY) {
+							if g.Apply(game.Command{Type: game.CmdSellTower, X: g.Manager.SelectedTowerX, Y: g.Manager.SelectedTowerY}) {
 								g.Manager.Mode = game.ModeNormal
 							}
 						}
+					case '4', '5':
+						if g.Manager.State != game.StateMenu && g.Manager.State != game.StateQuitConfirm && g.Manager.Mode == game.ModeSelect && !g.Manager.SelectingWallTarget && !g.Manager.SelectingWallRemoveTarget {
+							g.Apply(game.Command{Type: game.CmdUpgradeTower, X: g.Manager.SelectedTowerX, Y: g.Manager.SelectedTowerY, Choice: int(e.Rune() - '4')})
+						}
+					case '6':
+						if g.Manager.State != game.StateMenu && g.Manager.State != game.StateQuitConfirm && g.Manager.Mode == game.ModeSelect && !g.Manager.SelectingWallTarget && !g.Manager.SelectingWallRemoveTarget {
+							g.Apply(game.Command{Type: game.CmdCycleTargeting, X: g.Manager.SelectedTowerX, Y: g.Manager.SelectedTowerY})
+						}
+					case '7':
+						if g.Manager.State != game.StateMenu && g.Manager.State != game.StateQuitConfirm && g.Manager.Mode == game.ModeSelect && !g.Manager.SelectingWallTarget && !g.Manager.SelectingWallRemoveTarget {
+							g.Apply(game.Command{Type: game.CmdToggleRetarget, X: g.Manager.SelectedTowerX, Y: g.Manager.SelectedTowerY})
+						}
 					}
 				}
 			}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"log"
+
+	"github.com/gdamore/tcell/v2"
+
+	"terminal-td/internal/editor"
+	"terminal-td/internal/enemies"
+	mapdata "terminal-td/internal/map"
+	"terminal-td/internal/render"
+)
+
+// waveEditor is the wave editor screen: a list of maps, then the waves of the chosen one.
+type waveEditor struct {
+	maps      []mapdata.MapInfo
+	openIndex int
+	we        *editor.WaveEditor // nil while choosing a map
+	discard   bool               // ESC was pressed once with unsaved changes
+}
+
+func newWaveEditor(maps []mapdata.MapInfo) *waveEditor {
+	return &waveEditor{maps: maps}
+}
+
+func (w *waveEditor) draw(screen tcell.Screen) {
+	if w.we == nil {
+		var labels []string
+		for _, info := range w.maps {
+			labels = append(labels, render.MapLabel(info))
+		}
+		render.DrawEditorOpen(screen, "WAVE EDITOR", labels, w.openIndex)
+		return
+	}
+	render.DrawWaveEditor(screen, w.we)
+}
+
+// open loads the waves of the selected map.
+func (w *waveEditor) open() {
+	if w.openIndex >= len(w.maps) {
+		return
+	}
+	id := w.maps[w.openIndex].ID
+	m, err := mapdata.LoadMapByID(id)
+	if err != nil {
+		log.Printf("ERROR: wave editor: load map %q: %v", id, err)
+		return
+	}
+	db, err := enemies.DefaultEnemies()
+	if err != nil {
+		log.Printf("ERROR: wave editor: load enemies: %v", err)
+		return
+	}
+	w.we = editor.NewWaveEditor(m, db)
+	log.Printf("DEBUG: wave editor: editing waves of map %q", id)
+}
+
+func (w *waveEditor) handleKey(e *tcell.EventKey) editorAction {
+	if w.we == nil {
+		return w.handleOpenKey(e)
+	}
+	we := w.we
+	if e.Key() != tcell.KeyEscape {
+		w.discard = false
+	}
+	switch e.Key() {
+	case tcell.KeyEscape:
+		if we.Dirty && !w.discard {
+			w.discard = true
+			we.Status = "Unsaved changes: CTRL+S to save, ESC again to discard them"
+			return editorNone
+		}
+		return editorClose
+	case tcell.KeyUp:
+		we.SelectGroup(-1)
+	case tcell.KeyDown:
+		we.SelectGroup(1)
+	case tcell.KeyLeft:
+		we.SelectField(-1)
+	case tcell.KeyRight:
+		we.SelectField(1)
+	case tcell.KeyPgUp:
+		we.SelectWave(-1)
+	case tcell.KeyPgDn:
+		we.SelectWave(1)
+	case tcell.KeyDelete:
+		we.RemoveGroup()
+	case tcell.KeyCtrlS:
+		if path, err := we.Save(); err != nil {
+			we.Status = "Not saved: " + err.Error()
+		} else {
+			we.Status = "Saved to " + path
+		}
+	case tcell.KeyRune:
+		switch e.Rune() {
+		case 'w', 'W':
+			we.SelectGroup(-1)
+		case 's', 'S':
+			we.SelectGroup(1)
+		case 'a', 'A':
+			we.SelectField(-1)
+		case 'd', 'D':
+			we.SelectField(1)
+		case '[':
+			we.SelectWave(-1)
+		case ']':
+			we.SelectWave(1)
+		case '+', '=':
+			we.Adjust(1)
+		case '-':
+			we.Adjust(-1)
+		case 'g', 'G':
+			we.AddGroup()
+		case 'x', 'X':
+			we.RemoveGroup()
+		case 'n', 'N':
+			we.AddWave()
+		}
+	}
+	return editorNone
+}
+
+func (w *waveEditor) handleOpenKey(e *tcell.EventKey) editorAction {
+	switch e.Key() {
+	case tcell.KeyEscape:
+		return editorClose
+	case tcell.KeyUp:
+		w.moveSelection(-1)
+	case tcell.KeyDown:
+		w.moveSelection(1)
+	case tcell.KeyEnter:
+		w.open()
+	case tcell.KeyRune:
+		switch e.Rune() {
+		case 'w', 'W':
+			w.moveSelection(-1)
+		case 's', 'S':
+			w.moveSelection(1)
+		case ' ':
+			w.open()
+		}
+	}
+	return editorNone
+}
+
+func (w *waveEditor) moveSelection(delta int) {
+	i := w.openIndex + delta
+	if i >= 0 && i < len(w.maps) {
+		w.openIndex = i
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"terminal-td/internal/game"
+	"terminal-td/internal/towers"
+)
+
+// Layout is a scripted build order: towers and walls are bought in order as soon as their wave
+// is next and the money is there.
+type Layout struct {
+	Money  int           `json:"money"` // starting money override (0 keeps the difficulty's)
+	Towers []LayoutTower `json:"towers"`
+	Walls  []LayoutWall  `json:"walls"`
+}
+
+// LayoutTower is a tower to place, with the upgrades to buy right after.
+type LayoutTower struct {
+	Tower     string `json:"tower"`
+	X         int    `json:"x"`
+	Y         int    `json:"y"`
+	Wave      int    `json:"wave"`      // build before this wave starts (0 or 1 means before the first)
+	Upgrades  []int  `json:"upgrades"`  // upgrade choices, 0 for the first branch
+	Targeting string `json:"targeting"` // optional targeting mode override
+}
+
+// LayoutWall links two layout towers once both are built.
+type LayoutWall struct {
+	A    [2]int `json:"a"`
+	B    [2]int `json:"b"`
+	Wave int    `json:"wave"`
+}
+
+func loadLayout(path string) (*Layout, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var l Layout
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("layout decode: %w", err)
+	}
+	for i, t := range l.Towers {
+		if t.Tower == "" {
+			return nil, fmt.Errorf("layout tower %d has no tower type", i)
+		}
+		if t.Targeting != "" {
+			if _, err := towers.ParseTargetingMode(t.Targeting); err != nil {
+				return nil, fmt.Errorf("layout tower %d: %w", i, err)
+			}
+		}
+	}
+	return &l, nil
+}
+
+// builder works through a layout during a run.
+type builder struct {
+	layout      *Layout
+	nextTower   int
+	placed      bool // the tower at nextTower is built and its upgrades are being bought
+	nextUpgrade int
+	nextWall    int
+	failed      []string // build steps that can never succeed (blocked tile, unknown type or upgrade)
+}
+
+// build buys every due layout item in order, stopping at the first one that is not affordable yet.
+func (b *builder) build(g *game.Game) {
+	upcoming := g.Wave.Next + 1
+	for b.nextTower < len(b.layout.Towers) {
+		t := b.layout.Towers[b.nextTower]
+		if !b.placed {
+			if max(t.Wave, 1) > upcoming {
+				break
+			}
+			def := g.TowerDB.Get(t.Tower)
+			if def == nil || !g.CanPlaceTower(t.X, t.Y) {
+				b.fail("tower %s at (%d,%d) cannot be placed", t.Tower, t.X, t.Y)
+				b.nextTower++
+				continue
+			}
+			if g.Money < def.Cost {
+				return
+			}
+			g.Apply(game.Command{Type: game.CmdPlaceTower, X: t.X, Y: t.Y, Tower: t.Tower})
+			if t.Targeting != "" {
+				mode, _ := towers.ParseTargetingMode(t.Targeting)
+				g.GetTowerAt(t.X, t.Y).Targeting = mode
+			}
+			b.placed = true
+			b.nextUpgrade = 0
+		}
+		for b.nextUpgrade < len(t.Upgrades) {
+			choice := t.Upgrades[b.nextUpgrade]
+			options := g.TowerUpgrades(t.X, t.Y)
+			if choice < 0 || choice >= len(options) {
+				b.fail("upgrade %d of %s at (%d,%d) does not exist", choice, t.Tower, t.X, t.Y)
+				b.nextUpgrade = len(t.Upgrades)
+				break
+			}
+			if !g.Apply(game.Command{Type: game.CmdUpgradeTower, X: t.X, Y: t.Y, Choice: choice}) {
+				return
+			}
+			b.nextUpgrade++
+		}
+		b.placed = false
+		b.nextTower++
+	}
+	for b.nextWall < len(b.layout.Walls) {
+		w := b.layout.Walls[b.nextWall]
+		if max(w.Wave, 1) > upcoming || g.GetTowerAt(w.A[0], w.A[1]) == nil || g.GetTowerAt(w.B[0], w.B[1]) == nil {
+			return
+		}
+		if !g.Apply(game.Command{Type: game.CmdAddWall, X: w.A[0], Y: w.A[1], X2: w.B[0], Y2: w.B[1]}) {
+			b.fail("wall (%d,%d)-(%d,%d) cannot be built", w.A[0], w.A[1], w.B[0], w.B[1])
+		}
+		b.nextWall++
+	}
+}
+
+func (b *builder) fail(format string, args ...any) {
+	b.failed = append(b.failed, fmt.Sprintf(format, args...))
+}
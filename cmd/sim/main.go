@@ -0,0 +1,253 @@
+// Command sim runs games headless at full speed for balance testing: it plays a scripted tower
+// layout against a map's waves for one or more seeds and reports how each run went.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+
+	"terminal-td/internal/content"
+	"terminal-td/internal/enemies"
+	"terminal-td/internal/game"
+	mapdata "terminal-td/internal/map"
+	"terminal-td/internal/waves"
+)
+
+// inputs are the parsed files shared by every run. Each run parses its own map and waves
+// because games modify them.
+type inputs struct {
+	mapID      string
+	mapData    []byte // map file contents, nil when mapID names a built-in map
+	waveData   []byte // nil uses the map's built-in waves
+	enemyDB    *enemies.EnemyDatabase
+	layout     *Layout
+	difficulty string
+	endless    bool
+	maxWaves   int // endless runs that clear this many waves count as won
+	maxTicks   uint64
+}
+
+func main() {
+	mapFlag := flag.String("map", "classic", "Built-in map ID or path to a map JSON file")
+	wavesPath := flag.String("waves", "", "Wave JSON file (default: the map's built-in waves)")
+	enemiesPath := flag.String("enemies", "", "Enemy JSON file (default: built-in enemies)")
+	layoutPath := flag.String("layout", "", "Tower layout JSON file (required)")
+	difficultyID := flag.String("difficulty", "", "Difficulty preset ID (default: normal)")
+	endless := flag.Bool("endless", false, "Keep generating waves after the scripted ones (seeded)")
+	maxWaves := flag.Int("max-waves", 20, "Endless runs that clear this many waves count as won")
+	seeds := flag.Int("seeds", 1, "Number of seeds to run")
+	firstSeed := flag.Int64("seed", 1, "First seed; runs use seed, seed+1, ...")
+	parallel := flag.Int("parallel", runtime.NumCPU(), "Runs to simulate at once")
+	format := flag.String("format", "text", "Output format: text, json or csv")
+	maxTicks := flag.Uint64("max-ticks", 200000, "Give up on a run after this many simulation steps")
+	contentDir := flag.String("content-dir", "", "Also load maps, waves, enemies and towers from a content directory, like the game does (default: built-in data only)")
+	verbose := flag.Bool("v", false, "Log game debug output to stderr")
+	flag.Parse()
+
+	if *contentDir != "" {
+		content.SetDir(*contentDir)
+	} else {
+		content.UseBuiltinOnly()
+	}
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+	if *layoutPath == "" {
+		fatalf("-layout is required")
+	}
+	var write func(io.Writer, []Report) error
+	switch *format {
+	case "text":
+		write = writeText
+	case "json":
+		write = writeJSON
+	case "csv":
+		write = writeCSV
+	default:
+		fatalf("unknown format %q (want text, json or csv)", *format)
+	}
+
+	in, err := loadInputs(*mapFlag, *wavesPath, *enemiesPath, *layoutPath)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	in.difficulty = *difficultyID
+	in.endless = *endless
+	in.maxWaves = *maxWaves
+	in.maxTicks = *maxTicks
+
+	reports := make([]Report, 0, *seeds)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan int64)
+	for range max(*parallel, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for seed := range jobs {
+				r, err := simulate(in, seed)
+				if err != nil {
+					fatalf("seed %d: %v", seed, err)
+				}
+				mu.Lock()
+				reports = append(reports, r)
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range int64(*seeds) {
+		jobs <- *firstSeed + i
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Seed < reports[j].Seed })
+	if err := write(os.Stdout, reports); err != nil {
+		fatalf("write report: %v", err)
+	}
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "sim: "+format+"\n", args...)
+	os.Exit(1)
+}
+
+// loadInputs reads and validates every input file once, so bad files fail before any run starts.
+func loadInputs(mapFlag, wavesPath, enemiesPath, layoutPath string) (*inputs, error) {
+	in := &inputs{}
+
+	var m *mapdata.GameMap
+	if data, err := os.ReadFile(mapFlag); err == nil {
+		in.mapData = data
+		m, err = mapdata.LoadMapBytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("map %s: %w", mapFlag, err)
+		}
+	} else {
+		m, err = mapdata.LoadMapByID(mapFlag)
+		if err != nil {
+			return nil, err
+		}
+	}
+	in.mapID = m.ID
+
+	var err error
+	if enemiesPath != "" {
+		data, err := os.ReadFile(enemiesPath)
+		if err != nil {
+			return nil, err
+		}
+		if in.enemyDB, err = enemies.LoadEnemiesBytes(data); err != nil {
+			return nil, fmt.Errorf("enemies %s: %w", enemiesPath, err)
+		}
+	} else if in.enemyDB, err = enemies.DefaultEnemies(); err != nil {
+		return nil, err
+	}
+
+	var waveDefs []waves.WaveDef
+	if wavesPath != "" {
+		if in.waveData, err = os.ReadFile(wavesPath); err != nil {
+			return nil, err
+		}
+		if waveDefs, err = waves.LoadWavesBytes(in.waveData); err != nil {
+			return nil, fmt.Errorf("waves %s: %w", wavesPath, err)
+		}
+	} else if waveDefs, err = waves.LoadWavesForMap(m.ID); err != nil {
+		return nil, err
+	}
+	spawnIDs := make(map[string]bool)
+	for _, s := range m.Spawns {
+		spawnIDs[s.ID] = true
+	}
+	if err := waves.ValidateWavesAgainstMap(waveDefs, spawnIDs); err != nil {
+		return nil, err
+	}
+	for _, w := range waveDefs {
+		for _, g := range w.Groups {
+			if in.enemyDB.Get(g.EnemyType) == nil {
+				return nil, fmt.Errorf("wave %d uses unknown enemy type %q", w.Wave, g.EnemyType)
+			}
+		}
+	}
+
+	if in.layout, err = loadLayout(layoutPath); err != nil {
+		return nil, fmt.Errorf("layout %s: %w", layoutPath, err)
+	}
+	return in, nil
+}
+
+// simulate plays one run to the end (or maxTicks) as fast as possible.
+func simulate(in *inputs, seed int64) (Report, error) {
+	var m *mapdata.GameMap
+	var err error
+	if in.mapData != nil {
+		m, err = mapdata.LoadMapBytes(in.mapData)
+	} else {
+		m, err = mapdata.LoadMapByID(in.mapID)
+	}
+	if err != nil {
+		return Report{}, err
+	}
+	var waveDefs []waves.WaveDef
+	if in.waveData != nil {
+		waveDefs, err = waves.LoadWavesBytes(in.waveData)
+	} else {
+		waveDefs, err = waves.LoadWavesForMap(m.ID)
+	}
+	if err != nil {
+		return Report{}, err
+	}
+
+	g := game.NewGameFromData(m, in.enemyDB, waveDefs, game.Options{Endless: in.endless, Seed: seed, Difficulty: in.difficulty})
+	if in.layout.Money > 0 {
+		g.Money = in.layout.Money
+	}
+	g.Begin()
+
+	b := &builder{layout: in.layout}
+	var money []int
+	started := 0
+	survived := func() bool { return in.endless && g.Score.WavesCleared >= in.maxWaves }
+	for g.Tick < in.maxTicks && g.Manager.State != game.StateWon && g.Manager.State != game.StateLost && !survived() {
+		b.build(g)
+		g.Step()
+		for ; started < g.Wave.Next; started++ {
+			money = append(money, g.Money)
+		}
+	}
+	money = append(money, g.Money)
+
+	r := Report{
+		Seed:         seed,
+		Result:       "timeout",
+		Ticks:        g.Tick,
+		WavesCleared: g.Score.WavesCleared,
+		BaseHP:       g.Base.HP,
+		BaseHPLost:   make([]int, len(g.Wave.Waves)),
+		Money:        money,
+		LeaksBySpawn: g.Stats.LeaksBySpawn,
+		EffectDamage: g.Stats.EffectDamage,
+		BuildErrors:  b.failed,
+	}
+	switch {
+	case g.Manager.State == game.StateWon || survived():
+		r.Result = "won"
+	case g.Manager.State == game.StateLost:
+		r.Result = "lost"
+	}
+	copy(r.BaseHPLost, g.Stats.BaseHPLost)
+	if r.LeaksBySpawn == nil {
+		r.LeaksBySpawn = map[string]int{}
+	}
+	for _, t := range g.Towers {
+		r.Towers = append(r.Towers, TowerReport{Tower: t.TypeID, X: t.X, Y: t.Y, Level: t.Level, Damage: t.DamageDealt})
+	}
+	return r, nil
+}
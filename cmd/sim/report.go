@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Report is the outcome of one simulated run.
+type Report struct {
+	Seed         int64          `json:"seed"`
+	Result       string         `json:"result"` // won, lost or timeout
+	Ticks        uint64         `json:"ticks"`
+	WavesCleared int            `json:"waves_cleared"`
+	BaseHP       int            `json:"base_hp"`
+	BaseHPLost   []int          `json:"base_hp_lost"` // per wave
+	Money        []int          `json:"money"`        // money when each wave started, then at the end
+	LeaksBySpawn map[string]int `json:"leaks_by_spawn"`
+	Towers       []TowerReport  `json:"towers"`
+	EffectDamage float64        `json:"effect_damage"`
+	BuildErrors  []string       `json:"build_errors,omitempty"`
+}
+
+// TowerReport is the damage one tower dealt.
+type TowerReport struct {
+	Tower  string  `json:"tower"`
+	X      int     `json:"x"`
+	Y      int     `json:"y"`
+	Level  int     `json:"level"`
+	Damage float64 `json:"damage"`
+}
+
+func writeJSON(w io.Writer, reports []Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(reports)
+}
+
+// writeCSV writes one row per measurement (seed, metric, key, value) so runs with different
+// wave counts and layouts fit the same columns.
+func writeCSV(w io.Writer, reports []Report) error {
+	cw := csv.NewWriter(w)
+	row := func(r Report, metric, key, value string) {
+		cw.Write([]string{strconv.FormatInt(r.Seed, 10), metric, key, value})
+	}
+	itoa := strconv.Itoa
+	ftoa := func(f float64) string { return strconv.FormatFloat(f, 'f', 1, 64) }
+
+	cw.Write([]string{"seed", "metric", "key", "value"})
+	for _, r := range reports {
+		row(r, "result", "", r.Result)
+		row(r, "ticks", "", strconv.FormatUint(r.Ticks, 10))
+		row(r, "waves_cleared", "", itoa(r.WavesCleared))
+		row(r, "base_hp", "", itoa(r.BaseHP))
+		for i, lost := range r.BaseHPLost {
+			row(r, "base_hp_lost", itoa(i+1), itoa(lost))
+		}
+		for i, money := range r.Money {
+			key := itoa(i + 1)
+			if i == len(r.Money)-1 {
+				key = "end"
+			}
+			row(r, "money", key, itoa(money))
+		}
+		for _, spawn := range sortedKeys(r.LeaksBySpawn) {
+			row(r, "leaks", spawn, itoa(r.LeaksBySpawn[spawn]))
+		}
+		for _, t := range r.Towers {
+			row(r, "tower_damage", fmt.Sprintf("%s@%d,%d", t.Tower, t.X, t.Y), ftoa(t.Damage))
+		}
+		row(r, "effect_damage", "", ftoa(r.EffectDamage))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeText(w io.Writer, reports []Report) error {
+	wins := 0
+	for _, r := range reports {
+		if r.Result == "won" {
+			wins++
+		}
+		fmt.Fprintf(w, "seed %d: %s after %d ticks, %d waves cleared, base HP %d\n", r.Seed, r.Result, r.Ticks, r.WavesCleared, r.BaseHP)
+		fmt.Fprintf(w, "  base HP lost per wave: %v\n", r.BaseHPLost)
+		fmt.Fprintf(w, "  money per wave:        %v\n", r.Money)
+		for _, spawn := range sortedKeys(r.LeaksBySpawn) {
+			fmt.Fprintf(w, "  leaks from %-10s %d\n", spawn+":", r.LeaksBySpawn[spawn])
+		}
+		for _, t := range r.Towers {
+			fmt.Fprintf(w, "  %-10s (%2d,%2d) L%d  %8.1f damage\n", t.Tower, t.X, t.Y, t.Level, t.Damage)
+		}
+		if r.EffectDamage > 0 {
+			fmt.Fprintf(w, "  status effects         %8.1f damage\n", r.EffectDamage)
+		}
+		for _, e := range r.BuildErrors {
+			fmt.Fprintf(w, "  build error: %s\n", e)
+		}
+	}
+	if len(reports) > 1 {
+		fmt.Fprintf(w, "won %d/%d (%.0f%%)\n", wins, len(reports), 100*float64(wins)/float64(len(reports)))
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
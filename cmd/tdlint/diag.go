@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"terminal-td/internal/content"
+)
+
+// Diagnostic is one problem found in a file. Path is a JSON path such as $.paths[1].points[2].
+type Diagnostic struct {
+	File     string
+	Path     string
+	Severity string // "error" or "warning"
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	loc := d.File
+	if d.Path != "" {
+		loc += ": " + d.Path
+	}
+	return fmt.Sprintf("%s: %s: %s", loc, d.Severity, d.Message)
+}
+
+// linter collects diagnostics across every file.
+type linter struct {
+	diags    []Diagnostic
+	errors   int
+	warnings int
+}
+
+func (l *linter) errorf(file, path, format string, args ...any) {
+	l.diags = append(l.diags, Diagnostic{File: file, Path: path, Severity: "error", Message: fmt.Sprintf(format, args...)})
+	l.errors++
+}
+
+func (l *linter) warnf(file, path, format string, args ...any) {
+	l.diags = append(l.diags, Diagnostic{File: file, Path: path, Severity: "warning", Message: fmt.Sprintf(format, args...)})
+	l.warnings++
+}
+
+// problems reports what a validator found in file as errors.
+func (l *linter) problems(file string, problems []content.Problem) {
+	for _, p := range problems {
+		l.errorf(file, p.Path, "%s", p.Message)
+	}
+}
+
+// decode reads file into v and returns its contents. Syntax and type errors are reported with
+// their line and column; unknown fields (usually typos) are reported as warnings.
+func (l *linter) decode(file string, v any) ([]byte, bool) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		l.errorf(file, "", "%v", err)
+		return nil, false
+	}
+	strict := json.NewDecoder(bytes.NewReader(data))
+	strict.DisallowUnknownFields()
+	err = strict.Decode(v)
+	if err != nil && strings.HasPrefix(err.Error(), "json: unknown field") {
+		l.warnf(file, "", "%s", strings.TrimPrefix(err.Error(), "json: "))
+		err = json.Unmarshal(data, v)
+	}
+	if err == nil {
+		return data, true
+	}
+
+	var syntax *json.SyntaxError
+	var typ *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntax):
+		line, col := position(data, syntax.Offset)
+		l.errorf(file, "", "line %d column %d: %v", line, col, err)
+	case errors.As(err, &typ):
+		line, col := position(data, typ.Offset)
+		l.errorf(file, fieldPath(typ.Field), "line %d column %d: %s value where %s is expected", line, col, typ.Value, typ.Type)
+	default:
+		l.errorf(file, "", "%v", err)
+	}
+	return nil, false
+}
+
+// fieldPath turns encoding/json's dotted field (waves.0.wave) into a JSON path ($.waves[0].wave).
+func fieldPath(field string) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, part := range strings.Split(field, ".") {
+		if _, err := strconv.Atoi(part); err == nil {
+			b.WriteString("[" + part + "]")
+		} else if part != "" {
+			b.WriteString("." + part)
+		}
+	}
+	return b.String()
+}
+
+// position converts a byte offset into a 1-based line and column.
+func position(data []byte, offset int64) (line, col int) {
+	offset = min(offset, int64(len(data)))
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	col = int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// print writes the diagnostics in the order they were found, then a summary.
+func (l *linter) print(w io.Writer) {
+	for _, d := range l.diags {
+		fmt.Fprintln(w, d)
+	}
+	if l.errors == 0 && l.warnings == 0 {
+		fmt.Fprintln(w, "no problems found")
+		return
+	}
+	fmt.Fprintf(w, "%d error(s), %d warning(s)\n", l.errors, l.warnings)
+}
@@ -0,0 +1,202 @@
+// Command tdlint validates map, wave and enemy files together and reports every problem it
+// finds with the file and JSON path, instead of stopping at the first error like the loaders.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"terminal-td/internal/content"
+	"terminal-td/internal/enemies"
+	mapdata "terminal-td/internal/map"
+	"terminal-td/internal/waves"
+)
+
+// mapFile is a map that decoded, with the IDs the wave checks need.
+type mapFile struct {
+	file     string
+	def      mapdata.MapDef
+	spawns   map[string]int // spawn ID -> index in def.Spawns
+	tiles    bool           // a tile map; def.Spawns is filled in from the tile layer
+	used     map[string]bool
+	data     []byte
+	valid    bool // no structural errors, so a failure to build the map is news
+	waveFile string
+}
+
+func main() {
+	mapsDir := flag.String("maps", "internal/map/data", "Directory of map JSON files")
+	wavesDir := flag.String("waves", "internal/waves/data", "Directory of wave JSON files (<map id>.json)")
+	enemiesPath := flag.String("enemies", "internal/enemies/data/enemies.json", "Enemy JSON file")
+	contentDir := flag.String("content-dir", "", "Also check a content directory, merged over the files above like the game does")
+	werror := flag.Bool("werror", false, "Exit with an error when there are warnings")
+	flag.Parse()
+
+	log.SetOutput(io.Discard)
+	l := &linter{}
+
+	mapDirs := []string{*mapsDir}
+	waveDirs := []string{*wavesDir}
+	enemyFiles := []string{*enemiesPath}
+	if *contentDir != "" {
+		mapDirs = append(mapDirs, filepath.Join(*contentDir, content.MapsDir))
+		waveDirs = append(waveDirs, filepath.Join(*contentDir, content.WavesDir))
+		enemyFiles = append(enemyFiles, jsonFiles(l, filepath.Join(*contentDir, content.EnemiesDir))...)
+	}
+
+	db := &enemies.EnemyDatabase{Enemies: make(map[string]enemies.EnemyDef)}
+	for i, file := range enemyFiles {
+		if !l.lintEnemies(file, db) && i == 0 {
+			db = nil // without the base enemies every enemy type would look unknown
+			break
+		}
+	}
+
+	// Later directories override earlier ones by map ID, as content does in the game.
+	maps := make(map[string]*mapFile)
+	for _, dir := range mapDirs {
+		inDir := make(map[string]string)
+		for _, file := range jsonFiles(l, dir) {
+			m := l.lintMap(file)
+			if m == nil {
+				continue
+			}
+			if prev, ok := inDir[m.def.ID]; ok {
+				l.errorf(file, "$.id", "map id %q is already used by %s", m.def.ID, prev)
+				continue
+			}
+			inDir[m.def.ID] = file
+			maps[m.def.ID] = m
+		}
+	}
+
+	waveFiles := make(map[string]string)
+	for _, dir := range waveDirs {
+		for _, file := range jsonFiles(l, dir) {
+			waveFiles[strings.TrimSuffix(filepath.Base(file), ".json")] = file
+		}
+	}
+	for _, id := range sortedKeys(waveFiles) {
+		file := waveFiles[id]
+		m := maps[id]
+		if m == nil {
+			l.warnf(file, "$", "no map with id %q uses these waves", id)
+		} else {
+			m.waveFile = file
+		}
+		l.lintWaves(file, m, db)
+	}
+
+	for _, id := range sortedKeys(maps) {
+		m := maps[id]
+		if m.waveFile == "" {
+			l.errorf(m.file, "$.id", "no waves file %s.json in %s for map %q", id, strings.Join(waveDirs, " or "), id)
+		}
+		l.lintUnusedSpawns(m)
+		l.lintReachability(m)
+	}
+
+	l.print(os.Stdout)
+	if l.errors > 0 || (*werror && l.warnings > 0) {
+		os.Exit(1)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jsonFiles lists the .json files in dir, sorted.
+func jsonFiles(l *linter, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		l.errorf(dir, "", "%v", err)
+		return nil
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	return files
+}
+
+// lintEnemies checks every enemy in file on its own, adds the ones that pass to db (replacing
+// enemies with the same ID) and then checks the references between them. It reports whether the
+// file decoded.
+func (l *linter) lintEnemies(file string, db *enemies.EnemyDatabase) bool {
+	var defs struct {
+		Enemies []enemies.EnemyDef `json:"enemies"`
+	}
+	if _, ok := l.decode(file, &defs); !ok {
+		return false
+	}
+	if len(defs.Enemies) == 0 {
+		l.errorf(file, "$.enemies", "no enemies defined")
+	}
+	seen := make(map[string]bool)
+	for i, def := range defs.Enemies {
+		path := fmt.Sprintf("$.enemies[%d]", i)
+		if err := def.Validate(); err != nil {
+			l.errorf(file, path, "%v", err)
+			continue
+		}
+		if seen[def.ID] {
+			l.errorf(file, path+".id", "duplicate enemy id %q", def.ID)
+			continue
+		}
+		seen[def.ID] = true
+		db.Enemies[def.ID] = def
+	}
+	if err := db.ValidateChildren(); err != nil {
+		l.errorf(file, "$.enemies", "%v", err)
+	}
+	return true
+}
+
+// lintWaves checks a wave file, and its spawn IDs against m when the map is known.
+func (l *linter) lintWaves(file string, m *mapFile, db *enemies.EnemyDatabase) {
+	var defs struct {
+		Waves []waves.WaveDef `json:"waves"`
+	}
+	if _, ok := l.decode(file, &defs); !ok {
+		return
+	}
+	if len(defs.Waves) == 0 {
+		l.errorf(file, "$.waves", "no waves defined")
+	}
+	l.problems(file, waves.ValidateWaves(defs.Waves))
+	for i, wave := range defs.Waves {
+		path := fmt.Sprintf("$.waves[%d]", i)
+		if wave.Wave > 0 && wave.Wave != i+1 {
+			l.warnf(file, path+".wave", "wave number %d but it is wave %d in play order", wave.Wave, i+1)
+		}
+		for j, group := range wave.Groups {
+			gpath := fmt.Sprintf("%s.groups[%d]", path, j)
+			if group.SpawnID != "" && m != nil && m.def.ID != "" {
+				if _, ok := m.spawns[group.SpawnID]; !ok {
+					l.errorf(file, gpath+".spawn_id", "spawn %q does not exist in map %q", group.SpawnID, m.def.ID)
+				}
+				m.used[group.SpawnID] = true
+			}
+			if group.EnemyType != "" && db != nil && db.Get(group.EnemyType) == nil {
+				l.errorf(file, gpath+".enemy_type", "unknown enemy type %q", group.EnemyType)
+			}
+			if group.StartDelay < 0 {
+				l.warnf(file, gpath+".start_delay", "negative start_delay %g spawns immediately", group.StartDelay)
+			}
+		}
+	}
+}
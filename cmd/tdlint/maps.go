@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+
+	"terminal-td/internal/flow"
+	mapdata "terminal-td/internal/map"
+)
+
+// lintMap checks a map file's structure. It returns nil when the file does not decode.
+func (l *linter) lintMap(file string) *mapFile {
+	m := &mapFile{file: file, spawns: make(map[string]int), used: make(map[string]bool)}
+	data, ok := l.decode(file, &m.def)
+	if !ok {
+		return nil
+	}
+	m.data = data
+	def := &m.def
+	if def.ID == "" {
+		l.errorf(file, "$.id", "empty map id")
+	}
+	problems := def.Validate()
+	l.problems(file, problems)
+	m.valid = def.ID != "" && len(problems) == 0
+	if len(def.Tiles) > 0 {
+		// Spawns come from the tile layer, and only once it is valid.
+		m.tiles = true
+		def.Spawns = nil
+		if len(problems) == 0 {
+			l.tileSpawns(m)
+		}
+		return m
+	}
+
+	starts := make(map[string]bool)
+	for i, s := range def.Spawns {
+		if _, ok := m.spawns[s.ID]; s.ID != "" && !ok {
+			m.spawns[s.ID] = i
+		}
+	}
+	for _, pd := range def.Paths {
+		if si, ok := m.spawns[pd.SpawnID]; ok && len(pd.Points) > 0 {
+			if start := pd.Points[0]; start.X == def.Spawns[si].X && start.Y == def.Spawns[si].Y {
+				starts[pd.SpawnID] = true
+			}
+		}
+	}
+	for i, s := range def.Spawns {
+		if s.ID != "" && !starts[s.ID] {
+			l.warnf(file, fmt.Sprintf("$.spawns[%d]", i), "no path starts at spawn %q", s.ID)
+		}
+	}
+	return m
+}
+
+// tileSpawns builds a valid tile map and records its spawns for the wave checks.
+func (l *linter) tileSpawns(m *mapFile) {
+	gm, err := mapdata.LoadMapBytes(m.data)
+	if err != nil {
+		l.errorf(m.file, "$.tiles", "%v", err)
+		return
+	}
+	for i, s := range gm.Spawns {
+		m.def.Spawns = append(m.def.Spawns, mapdata.SpawnDef{ID: s.ID, X: s.X, Y: s.Y})
+		m.spawns[s.ID] = i
+	}
+}
+
+// spawnPath is the JSON path of spawn i: its entry in spawns, or its row in a tile map.
+func (m *mapFile) spawnPath(i int) string {
+	if m.tiles {
+		return fmt.Sprintf("$.tiles[%d]", m.def.Spawns[i].Y)
+	}
+	return fmt.Sprintf("$.spawns[%d]", i)
+}
+
+// lintUnusedSpawns warns about spawns no wave sends enemies from.
+func (l *linter) lintUnusedSpawns(m *mapFile) {
+	if m.waveFile == "" {
+		return
+	}
+	for i, s := range m.def.Spawns {
+		if s.ID != "" && !m.used[s.ID] {
+			l.warnf(m.file, m.spawnPath(i), "spawn %q is not used by any wave in %s", s.ID, m.waveFile)
+		}
+	}
+}
+
+// lintReachability builds the map the way the game does and checks that every spawn reaches the base through the flow field.
+func (l *linter) lintReachability(m *mapFile) {
+	gm, err := mapdata.LoadMapBytes(m.data)
+	if err != nil {
+		if m.valid {
+			l.errorf(m.file, "$", "%v", err)
+		}
+		return
+	}
+	walkable := flow.BuildWalkability(gm.Grid)
+	field := flow.Compute(gm.Grid.Width, gm.Grid.Height, walkable, gm.Base.X, gm.Base.Y)
+	for i, s := range gm.Spawns {
+		if dist, _ := field.At(s.X, s.Y); dist >= flow.Inf {
+			l.errorf(m.file, m.spawnPath(i), "spawn %q cannot reach the base", s.ID)
+		}
+	}
+}
@@ -2,13 +2,15 @@ module terminal-td
 
 go 1.25.7
 
-require github.com/gdamore/tcell/v2 v2.13.8
+require (
+	github.com/gdamore/tcell/v2 v2.13.8
+	golang.org/x/mod v0.33.0
+)
 
 require (
 	github.com/gdamore/encoding v1.0.1 // indirect
 	github.com/lucasb-eyer/go-colorful v1.3.0 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
-	golang.org/x/mod v0.33.0 // indirect
 	golang.org/x/sys v0.40.0 // indirect
 	golang.org/x/term v0.39.0 // indirect
 	golang.org/x/text v0.33.0 // indirect
@@ -15,8 +15,9 @@ const (
 )
 
 type Config struct {
-	Version         int  `json:"config_version"`
-	CheckForUpdates bool `json:"check_for_updates"`
+	Version         int      `json:"config_version"`
+	CheckForUpdates bool     `json:"check_for_updates"`
+	DisabledMods    []string `json:"disabled_mods,omitempty"` // IDs of installed mods switched off in the Mods menu
 }
 
 func Dir() (string, error) {
@@ -0,0 +1,31 @@
+package content
+
+import "fmt"
+
+// Problem is something wrong with a data file, at a JSON path such as $.waves[2].groups[0].count.
+// Validators return every problem they find; loaders stop at the first.
+type Problem struct {
+	Path    string
+	Message string
+}
+
+func (p Problem) Error() string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return p.Path + ": " + p.Message
+}
+
+// Problemf returns a Problem at path.
+func Problemf(path, format string, args ...any) Problem {
+	return Problem{Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+// Under prefixes the paths of problems found inside a value with the value's own path, e.g.
+// Under("$.waves[2]", ...) turns .groups[0].count into $.waves[2].groups[0].count.
+func Under(path string, problems []Problem) []Problem {
+	for i := range problems {
+		problems[i].Path = path + problems[i].Path
+	}
+	return problems
+}
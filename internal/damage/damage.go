@@ -0,0 +1,60 @@
+package damage
+
+import (
+	"fmt"
+	"math"
+)
+
+// Type is the kind of damage a hit deals; it decides which enemy defences apply.
+type Type string
+
+const (
+	Physical Type = "physical" // reduced by flat armor, then by physical resistance
+	Magic    Type = "magic"    // ignores armor, reduced by magic resistance
+	True     Type = "true"     // ignores armor and resistances
+)
+
+// Types lists all damage types in display order.
+var Types = []Type{Physical, Magic, True}
+
+// Parse parses a JSON damage type; empty means Physical.
+func Parse(s string) (Type, error) {
+	if s == "" {
+		return Physical, nil
+	}
+	for _, t := range Types {
+		if string(t) == s {
+			return t, nil
+		}
+	}
+	return Physical, fmt.Errorf("unknown damage type %q", s)
+}
+
+// MinDamageFraction is the share of raw damage that always gets through armor.
+const MinDamageFraction = 0.1
+
+// MaxResistance caps resistances so no enemy is fully immune to a damage type.
+const MaxResistance = 0.9
+
+// Defense is an enemy's damage mitigation.
+type Defense struct {
+	Armor       float64          // flat reduction of physical damage per hit
+	Resistances map[Type]float64 // fraction of damage ignored per type (negative = weakness)
+}
+
+// Resolve returns the damage dealt by a raw hit of type t against d.
+// This is the single place hit damage is computed; callers apply vulnerability multipliers afterwards.
+func Resolve(raw float64, t Type, d Defense) float64 {
+	if raw <= 0 {
+		return 0
+	}
+	if t == True {
+		return raw
+	}
+	dmg := raw
+	if t == Physical {
+		dmg = math.Max(raw-d.Armor, raw*MinDamageFraction)
+	}
+	resist := math.Min(d.Resistances[t], MaxResistance)
+	return dmg * (1 - resist)
+}
@@ -0,0 +1,17 @@
+package difficulty
+
+import (
+	"embed"
+)
+
+//go:embed data/presets.json
+var defaultPresetsFS embed.FS
+
+// DefaultPresets returns the built-in difficulty presets.
+func DefaultPresets() (*PresetDatabase, error) {
+	data, err := defaultPresetsFS.ReadFile("data/presets.json")
+	if err != nil {
+		return nil, err
+	}
+	return LoadPresetsBytes(data)
+}
@@ -0,0 +1,74 @@
+package difficulty
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+)
+
+// defaultStartingMoney is used when a preset omits starting_money.
+const defaultStartingMoney = 500
+
+// LoadPresets reads difficulty presets from r and returns a database.
+func LoadPresets(r io.Reader) (*PresetDatabase, error) {
+	var defs struct {
+		Presets []Preset `json:"presets"`
+	}
+	if err := json.NewDecoder(r).Decode(&defs); err != nil {
+		return nil, fmt.Errorf("difficulty decode: %w", err)
+	}
+	db := &PresetDatabase{
+		Presets: make(map[string]Preset),
+	}
+	for _, p := range defs.Presets {
+		if p.ID == "" {
+			return nil, fmt.Errorf("difficulty preset with empty id")
+		}
+		if p.Name == "" {
+			p.Name = p.ID
+		}
+		multipliers := []struct {
+			name string
+			v    *float64
+		}{
+			{"hp_multiplier", &p.HPMultiplier},
+			{"speed_multiplier", &p.SpeedMultiplier},
+			{"count_multiplier", &p.CountMultiplier},
+			{"interval_multiplier", &p.IntervalMultiplier},
+			{"reward_multiplier", &p.RewardMultiplier},
+			{"base_hp_multiplier", &p.BaseHPMultiplier},
+		}
+		for _, m := range multipliers {
+			if *m.v < 0 {
+				return nil, fmt.Errorf("difficulty %q has invalid %s %f", p.ID, m.name, *m.v)
+			}
+			if *m.v == 0 {
+				*m.v = 1
+			}
+		}
+		if p.StartingMoney == 0 {
+			p.StartingMoney = defaultStartingMoney
+		}
+		if p.StartingMoney < 0 {
+			return nil, fmt.Errorf("difficulty %q has invalid starting_money %d", p.ID, p.StartingMoney)
+		}
+		if _, ok := db.Presets[p.ID]; ok {
+			return nil, fmt.Errorf("duplicate difficulty id %q", p.ID)
+		}
+		db.Presets[p.ID] = p
+		db.Order = append(db.Order, p.ID)
+		log.Printf("loaded difficulty: id=%q hp=%.2f speed=%.2f count=%.2f interval=%.2f reward=%.2f base_hp=%.2f money=%d",
+			p.ID, p.HPMultiplier, p.SpeedMultiplier, p.CountMultiplier, p.IntervalMultiplier, p.RewardMultiplier, p.BaseHPMultiplier, p.StartingMoney)
+	}
+	if len(db.Order) == 0 {
+		return nil, fmt.Errorf("no difficulty presets")
+	}
+	return db, nil
+}
+
+// LoadPresetsBytes parses preset JSON from bytes (for embed or tests).
+func LoadPresetsBytes(data []byte) (*PresetDatabase, error) {
+	return LoadPresets(bytes.NewReader(data))
+}
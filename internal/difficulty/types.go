@@ -0,0 +1,82 @@
+package difficulty
+
+// DefaultPresetID is used when no preset is chosen.
+const DefaultPresetID = "normal"
+
+// Preset scales a run before it starts. Multipliers of 1 leave the map's data unchanged.
+type Preset struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	HPMultiplier       float64 `json:"hp_multiplier"`
+	SpeedMultiplier    float64 `json:"speed_multiplier"`
+	CountMultiplier    float64 `json:"count_multiplier"`    // enemies per spawn group
+	IntervalMultiplier float64 `json:"interval_multiplier"` // time between spawns within a group
+	RewardMultiplier   float64 `json:"reward_multiplier"`
+	BaseHPMultiplier   float64 `json:"base_hp_multiplier"`
+	StartingMoney      int     `json:"starting_money"`
+}
+
+// PresetDatabase holds all loaded presets.
+type PresetDatabase struct {
+	Presets map[string]Preset // id -> preset
+	Order   []string          // selection order, as declared in JSON
+}
+
+// Get returns a preset by ID ("" means DefaultPresetID), or nil if not found.
+func (db *PresetDatabase) Get(id string) *Preset {
+	if id == "" {
+		id = DefaultPresetID
+	}
+	p, ok := db.Presets[id]
+	if !ok {
+		return nil
+	}
+	return &p
+}
+
+// Index returns the selection index of id, or the default preset's index (0 if missing).
+func (db *PresetDatabase) Index(id string) int {
+	if id == "" {
+		id = DefaultPresetID
+	}
+	for i, pid := range db.Order {
+		if pid == id {
+			return i
+		}
+	}
+	for i, pid := range db.Order {
+		if pid == DefaultPresetID {
+			return i
+		}
+	}
+	return 0
+}
+
+// At returns the preset at selection index i (wraps around), or nil if empty.
+func (db *PresetDatabase) At(i int) *Preset {
+	if len(db.Order) == 0 {
+		return nil
+	}
+	i %= len(db.Order)
+	if i < 0 {
+		i += len(db.Order)
+	}
+	return db.Get(db.Order[i])
+}
+
+// Neutral returns a preset that changes nothing (used when the preset file is unavailable).
+func Neutral() Preset {
+	return Preset{
+		ID:                 DefaultPresetID,
+		Name:               "Normal",
+		HPMultiplier:       1,
+		SpeedMultiplier:    1,
+		CountMultiplier:    1,
+		IntervalMultiplier: 1,
+		RewardMultiplier:   1,
+		BaseHPMultiplier:   1,
+		StartingMoney:      defaultStartingMoney,
+	}
+}
@@ -0,0 +1,539 @@
+// Package editor is the in-game map editor: tiles painted with the cursor on a resizable grid,
+// undo/redo, a live check that every spawn reaches the base, and saving to the content directory
+// as a tile map.
+package editor
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"terminal-td/internal/content"
+	"terminal-td/internal/flow"
+	mapdata "terminal-td/internal/map"
+)
+
+const (
+	MinSize       = 5
+	MaxWidth      = 80
+	MaxHeight     = 25
+	DefaultWidth  = 40
+	DefaultHeight = 15
+	DefaultBaseHP = 20
+
+	maxHistory = 200
+)
+
+// Brushes are the tiles the editor paints, in palette order (keys 1-9, then 0).
+var Brushes = []mapdata.TileType{
+	mapdata.Empty,
+	mapdata.PathTile,
+	mapdata.SpawnTile,
+	mapdata.BaseTile,
+	mapdata.RockTile,
+	mapdata.WaterTile,
+	mapdata.BuildTile,
+	mapdata.MudTile,
+	mapdata.IceTile,
+	mapdata.HighGroundTile,
+}
+
+// BrushName is the palette label for a tile.
+func BrushName(t mapdata.TileType) string {
+	switch t {
+	case mapdata.PathTile:
+		return "path"
+	case mapdata.SpawnTile:
+		return "spawn"
+	case mapdata.BaseTile:
+		return "base"
+	case mapdata.RockTile:
+		return "rock"
+	case mapdata.WaterTile:
+		return "water"
+	case mapdata.BuildTile:
+		return "build"
+	case mapdata.MudTile:
+		return "mud"
+	case mapdata.IceTile:
+		return "ice"
+	case mapdata.HighGroundTile:
+		return "high"
+	}
+	return "empty"
+}
+
+// snapshot is one undo step: everything an edit can change.
+type snapshot struct {
+	id, name     string
+	tiles        [][]mapdata.TileType
+	spawns       []mapdata.SpawnPoint
+	baseX, baseY int
+	baseHP       int
+}
+
+// Prompt is a line of text being typed, e.g. a spawn name.
+type Prompt struct {
+	Label string
+	Value string
+	apply func(string) error
+}
+
+// Editor is a map being edited.
+type Editor struct {
+	ID     string
+	Name   string
+	Grid   *mapdata.Grid
+	Spawns []mapdata.SpawnPoint
+	BaseX  int // -1 when the map has no base yet
+	BaseY  int
+	BaseHP int
+
+	Pathing    mapdata.PathingDef // kept from the opened map; not edited here
+	SpawnChars map[string]rune    // legend characters of the opened map's spawns, reused on save
+
+	Brush   int // index into Brushes
+	CursorX int
+	CursorY int
+	Dirty   bool   // changed since the last save
+	Status  string // result of the last action, shown under the grid
+	Prompt  *Prompt
+
+	// Problems keeps the map from being saved or play-tested; updated after every edit.
+	Problems    []string
+	Unreachable map[string]bool // spawn IDs with no route to the base
+	Routes      [][]flow.Tile   // tiles between each reachable spawn and the base
+	undo, redo  []snapshot
+}
+
+// New returns an empty map of the default size.
+func New() *Editor {
+	e := &Editor{
+		Name:   "New Map",
+		Grid:   mapdata.NewGrid(DefaultWidth, DefaultHeight),
+		BaseX:  -1,
+		BaseY:  -1,
+		BaseHP: DefaultBaseHP,
+		Brush:  1,
+	}
+	e.CursorX, e.CursorY = DefaultWidth/2, DefaultHeight/2
+	e.check()
+	return e
+}
+
+// FromMap opens an existing map. Waypoint maps become tile maps: their drawn paths are kept and
+// enemies follow them through the flow field as before.
+func FromMap(m *mapdata.GameMap) *Editor {
+	e := &Editor{
+		ID:     m.ID,
+		Name:   m.Name,
+		Grid:   mapdata.NewGrid(m.Grid.Width, m.Grid.Height),
+		Spawns: append([]mapdata.SpawnPoint(nil), m.Spawns...),
+		BaseX:  m.Base.X,
+		BaseY:  m.Base.Y,
+		BaseHP: m.Base.HP,
+		Brush:  1,
+
+		Pathing:    m.Pathing,
+		SpawnChars: m.SpawnChars,
+	}
+	for y := range m.Grid.Tiles {
+		copy(e.Grid.Tiles[y], m.Grid.Tiles[y])
+	}
+	for y, row := range e.Grid.Tiles {
+		for x, t := range row {
+			if t == mapdata.SpawnTile && e.spawnAt(x, y) < 0 {
+				row[x] = mapdata.PathTile
+			}
+		}
+	}
+	e.CursorX, e.CursorY = e.Grid.Width/2, e.Grid.Height/2
+	e.check()
+	return e
+}
+
+func (e *Editor) spawnAt(x, y int) int {
+	for i, s := range e.Spawns {
+		if s.X == x && s.Y == y {
+			return i
+		}
+	}
+	return -1
+}
+
+// SpawnAtCursor returns the spawn under the cursor, if any.
+func (e *Editor) SpawnAtCursor() (mapdata.SpawnPoint, bool) {
+	if i := e.spawnAt(e.CursorX, e.CursorY); i >= 0 {
+		return e.Spawns[i], true
+	}
+	return mapdata.SpawnPoint{}, false
+}
+
+// MoveCursor moves the cursor, keeping it on the grid.
+func (e *Editor) MoveCursor(dx, dy int) {
+	e.CursorX = min(max(e.CursorX+dx, 0), e.Grid.Width-1)
+	e.CursorY = min(max(e.CursorY+dy, 0), e.Grid.Height-1)
+}
+
+// CycleBrush moves the brush selection by delta, wrapping around.
+func (e *Editor) CycleBrush(delta int) {
+	n := len(Brushes)
+	e.Brush = ((e.Brush+delta)%n + n) % n
+}
+
+// Paint puts the current brush under the cursor.
+func (e *Editor) Paint() {
+	e.paint(Brushes[e.Brush])
+}
+
+// Erase clears the tile under the cursor.
+func (e *Editor) Erase() {
+	e.paint(mapdata.Empty)
+}
+
+// paint sets the tile under the cursor. A spawn tile adds a spawn with a fresh ID; a base tile
+// moves the base, leaving path where it was so the route stays intact.
+func (e *Editor) paint(t mapdata.TileType) {
+	x, y := e.CursorX, e.CursorY
+	cur := e.Grid.Tiles[y][x]
+	if cur == t {
+		return
+	}
+	e.record()
+	if cur == mapdata.SpawnTile {
+		if i := e.spawnAt(x, y); i >= 0 {
+			e.Spawns = append(e.Spawns[:i], e.Spawns[i+1:]...)
+		}
+	}
+	if cur == mapdata.BaseTile {
+		e.BaseX, e.BaseY = -1, -1
+	}
+	switch t {
+	case mapdata.SpawnTile:
+		e.Spawns = append(e.Spawns, mapdata.SpawnPoint{ID: e.newSpawnID(), X: x, Y: y})
+	case mapdata.BaseTile:
+		if e.BaseX >= 0 {
+			e.Grid.Tiles[e.BaseY][e.BaseX] = mapdata.PathTile
+		}
+		e.BaseX, e.BaseY = x, y
+	}
+	e.Grid.Tiles[y][x] = t
+	e.changed("")
+}
+
+// newSpawnID returns "default" for the first spawn (the ID the built-in waves use), then
+// spawn2, spawn3, ...
+func (e *Editor) newSpawnID() string {
+	taken := make(map[string]bool)
+	for _, s := range e.Spawns {
+		taken[s.ID] = true
+	}
+	if !taken[mapdata.DefaultSpawnID] {
+		return mapdata.DefaultSpawnID
+	}
+	for n := 2; ; n++ {
+		if id := fmt.Sprintf("spawn%d", n); !taken[id] {
+			return id
+		}
+	}
+}
+
+// Resize grows or shrinks the grid by dw columns and dh rows at the right and bottom edges.
+// Spawns and the base outside the new grid are removed.
+func (e *Editor) Resize(dw, dh int) {
+	w := min(max(e.Grid.Width+dw, MinSize), MaxWidth)
+	h := min(max(e.Grid.Height+dh, MinSize), MaxHeight)
+	if w == e.Grid.Width && h == e.Grid.Height {
+		return
+	}
+	e.record()
+	grid := mapdata.NewGrid(w, h)
+	for y := 0; y < min(h, e.Grid.Height); y++ {
+		copy(grid.Tiles[y], e.Grid.Tiles[y][:min(w, e.Grid.Width)])
+	}
+	e.Grid = grid
+	spawns := e.Spawns[:0]
+	for _, s := range e.Spawns {
+		if s.X < w && s.Y < h {
+			spawns = append(spawns, s)
+		}
+	}
+	e.Spawns = spawns
+	if e.BaseX >= w || e.BaseY >= h {
+		e.BaseX, e.BaseY = -1, -1
+	}
+	e.MoveCursor(0, 0)
+	e.changed(fmt.Sprintf("Grid is now %dx%d", w, h))
+}
+
+// AdjustBaseHP changes the base HP by delta, keeping it at least 1.
+func (e *Editor) AdjustBaseHP(delta int) {
+	hp := max(e.BaseHP+delta, 1)
+	if hp == e.BaseHP {
+		return
+	}
+	e.record()
+	e.BaseHP = hp
+	e.changed("")
+}
+
+// RenameSpawn asks for a new ID for the spawn under the cursor.
+func (e *Editor) RenameSpawn() {
+	i := e.spawnAt(e.CursorX, e.CursorY)
+	if i < 0 {
+		e.Status = "Move the cursor onto a spawn to name it"
+		return
+	}
+	old := e.Spawns[i].ID
+	e.Prompt = &Prompt{Label: "Spawn name", Value: old, apply: func(id string) error {
+		if err := validID(id); err != nil {
+			return err
+		}
+		if id == old {
+			return nil
+		}
+		for _, s := range e.Spawns {
+			if s.ID == id {
+				return fmt.Errorf("another spawn is already called %q", id)
+			}
+		}
+		e.record()
+		e.Spawns[i].ID = id
+		e.changed(fmt.Sprintf("Spawn %q renamed to %q", old, id))
+		return nil
+	}}
+}
+
+// EditName asks for the map's display name.
+func (e *Editor) EditName() {
+	e.Prompt = &Prompt{Label: "Map name", Value: e.Name, apply: func(name string) error {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return fmt.Errorf("the name cannot be empty")
+		}
+		if name != e.Name {
+			e.record()
+			e.Name = name
+			e.changed("")
+		}
+		return nil
+	}}
+}
+
+// EditID asks for the map ID, which is also the file name it is saved under.
+func (e *Editor) EditID() {
+	e.Prompt = &Prompt{Label: "Map id", Value: e.ID, apply: func(id string) error {
+		if err := validID(id); err != nil {
+			return err
+		}
+		if id != e.ID {
+			e.record()
+			e.ID = id
+			e.changed("")
+		}
+		return nil
+	}}
+}
+
+// validID accepts IDs that are safe as file names: lower-case letters, digits, '-' and '_'.
+func validID(id string) error {
+	if id == "" {
+		return fmt.Errorf("the id cannot be empty")
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return fmt.Errorf("use only a-z, 0-9, '-' and '_'")
+		}
+	}
+	return nil
+}
+
+// Type adds r to the prompt.
+func (e *Editor) Type(r rune) {
+	if e.Prompt != nil && len(e.Prompt.Value) < 40 {
+		e.Prompt.Value += string(r)
+	}
+}
+
+// Backspace removes the prompt's last character.
+func (e *Editor) Backspace() {
+	if e.Prompt != nil && e.Prompt.Value != "" {
+		v := []rune(e.Prompt.Value)
+		e.Prompt.Value = string(v[:len(v)-1])
+	}
+}
+
+// Submit applies the prompt. On error the prompt stays open and Status says why.
+func (e *Editor) Submit() {
+	if e.Prompt == nil {
+		return
+	}
+	if err := e.Prompt.apply(e.Prompt.Value); err != nil {
+		e.Status = err.Error()
+		return
+	}
+	e.Prompt = nil
+}
+
+// CancelPrompt closes the prompt without applying it.
+func (e *Editor) CancelPrompt() {
+	e.Prompt = nil
+	e.Status = ""
+}
+
+// Undo reverts the last edit.
+func (e *Editor) Undo() {
+	if len(e.undo) == 0 {
+		e.Status = "Nothing to undo"
+		return
+	}
+	e.redo = append(e.redo, e.snapshot())
+	e.restore(e.undo[len(e.undo)-1])
+	e.undo = e.undo[:len(e.undo)-1]
+	e.Dirty = true
+	e.Status = "Undone"
+}
+
+// Redo applies the last undone edit again.
+func (e *Editor) Redo() {
+	if len(e.redo) == 0 {
+		e.Status = "Nothing to redo"
+		return
+	}
+	e.undo = append(e.undo, e.snapshot())
+	e.restore(e.redo[len(e.redo)-1])
+	e.redo = e.redo[:len(e.redo)-1]
+	e.Dirty = true
+	e.Status = "Redone"
+}
+
+// record saves the current state for undo, before an edit. Any redo history is dropped.
+func (e *Editor) record() {
+	e.undo = append(e.undo, e.snapshot())
+	if len(e.undo) > maxHistory {
+		e.undo = e.undo[1:]
+	}
+	e.redo = nil
+}
+
+func (e *Editor) changed(status string) {
+	e.Dirty = true
+	e.Status = status
+	e.check()
+}
+
+func (e *Editor) snapshot() snapshot {
+	tiles := make([][]mapdata.TileType, len(e.Grid.Tiles))
+	for y, row := range e.Grid.Tiles {
+		tiles[y] = append([]mapdata.TileType(nil), row...)
+	}
+	return snapshot{
+		id:     e.ID,
+		name:   e.Name,
+		tiles:  tiles,
+		spawns: append([]mapdata.SpawnPoint(nil), e.Spawns...),
+		baseX:  e.BaseX,
+		baseY:  e.BaseY,
+		baseHP: e.BaseHP,
+	}
+}
+
+func (e *Editor) restore(s snapshot) {
+	e.ID, e.Name = s.id, s.name
+	e.Grid = &mapdata.Grid{Width: len(s.tiles[0]), Height: len(s.tiles), Tiles: s.tiles}
+	e.Spawns = s.spawns
+	e.BaseX, e.BaseY, e.BaseHP = s.baseX, s.baseY, s.baseHP
+	e.MoveCursor(0, 0)
+	e.check()
+}
+
+// check recomputes the flow field from the base and lists what keeps the map from being played.
+func (e *Editor) check() {
+	e.Problems = nil
+	e.Unreachable = make(map[string]bool)
+	e.Routes = nil
+	if e.BaseX < 0 {
+		e.Problems = append(e.Problems, "no base: paint one with the base brush (4)")
+	}
+	if len(e.Spawns) == 0 {
+		e.Problems = append(e.Problems, "no spawns: paint one with the spawn brush (3)")
+	}
+	if e.BaseX >= 0 {
+		walkable := flow.BuildWalkability(e.Grid)
+		opts := flow.Options{Costs: flow.TerrainCosts(e.Grid), Diagonal: e.Pathing.Diagonal}
+		field := flow.ComputeWithOptions(e.Grid.Width, e.Grid.Height, walkable, e.BaseX, e.BaseY, opts)
+		for _, s := range e.Spawns {
+			if dist, _ := field.At(s.X, s.Y); dist >= flow.Inf {
+				e.Unreachable[s.ID] = true
+				e.Problems = append(e.Problems, fmt.Sprintf("spawn %q cannot reach the base", s.ID))
+				continue
+			}
+			if route := field.TracePath(s.X, s.Y, e.BaseX, e.BaseY); len(route) > 1 {
+				e.Routes = append(e.Routes, route[:len(route)-1])
+			}
+		}
+	}
+	if e.ID == "" {
+		e.Problems = append(e.Problems, "no map id: press I to set one")
+	}
+}
+
+// Def returns the map as a tile map definition.
+func (e *Editor) Def() *mapdata.MapDef {
+	m := &mapdata.GameMap{
+		ID:     e.ID,
+		Name:   e.Name,
+		Grid:   e.Grid,
+		Spawns: e.Spawns,
+		Base:   mapdata.BaseInfo{X: e.BaseX, Y: e.BaseY, HP: e.BaseHP},
+
+		Pathing:    e.Pathing,
+		SpawnChars: e.SpawnChars,
+	}
+	return m.TileDef()
+}
+
+// Map builds the map the way the game loads it from a file.
+func (e *Editor) Map() (*mapdata.GameMap, error) {
+	if len(e.Problems) > 0 {
+		return nil, fmt.Errorf("%s", e.Problems[0])
+	}
+	data, err := mapdata.MarshalMap(e.Def())
+	if err != nil {
+		return nil, err
+	}
+	return mapdata.LoadMapBytes(data)
+}
+
+// OverridesBuiltin reports whether saving would replace a built-in map with this one.
+func (e *Editor) OverridesBuiltin() bool {
+	return mapdata.IsBuiltin(e.ID)
+}
+
+// Save checks that the game will accept the map, then writes it to <content dir>/maps/<id>.json.
+// It returns the file's path.
+func (e *Editor) Save() (string, error) {
+	if len(e.Problems) > 0 {
+		return "", fmt.Errorf("cannot save: %s", e.Problems[0])
+	}
+	data, err := mapdata.MarshalMap(e.Def())
+	if err != nil {
+		return "", err
+	}
+	if _, err := mapdata.LoadMapBytes(data); err != nil {
+		return "", fmt.Errorf("cannot save: %w", err)
+	}
+	dir, err := content.Dir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, content.MapsDir, e.ID+".json")
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", err
+	}
+	e.Dirty = false
+	log.Printf("DEBUG: editor saved map %q to %s", e.ID, path)
+	return path, nil
+}
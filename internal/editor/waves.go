@@ -0,0 +1,296 @@
+package editor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"terminal-td/internal/content"
+	"terminal-td/internal/enemies"
+	mapdata "terminal-td/internal/map"
+	"terminal-td/internal/waves"
+)
+
+// Group fields the wave editor can change, in column order.
+const (
+	FieldSpawn = iota
+	FieldEnemy
+	FieldCount
+	FieldInterval
+	FieldDelay
+	fieldCount
+)
+
+// FieldNames are the column headers of the group table.
+var FieldNames = []string{"spawn", "enemy", "count", "interval", "delay"}
+
+// WaveEditor edits the waves of one map.
+type WaveEditor struct {
+	MapID    string
+	SpawnIDs []string // the map's spawns, in map order
+	EnemyIDs []string // known enemy types, sorted
+	EnemyDB  *enemies.EnemyDatabase
+	Waves    []waves.WaveDef
+
+	Wave   int // selected wave
+	Group  int // selected group of the selected wave
+	Field  int // selected column
+	Dirty  bool
+	Status string
+
+	loadErr error // the map's waves file exists but does not load; saving would overwrite it
+}
+
+// NewWaveEditor opens the waves of map m, or a single starter wave if the map has no waves file.
+// A waves file that fails to load is reported and never saved over.
+func NewWaveEditor(m *mapdata.GameMap, db *enemies.EnemyDatabase) *WaveEditor {
+	we := &WaveEditor{MapID: m.ID, EnemyDB: db}
+	for _, s := range m.Spawns {
+		we.SpawnIDs = append(we.SpawnIDs, s.ID)
+	}
+	for id := range db.Enemies {
+		we.EnemyIDs = append(we.EnemyIDs, id)
+	}
+	sort.Strings(we.EnemyIDs)
+
+	defs, err := waves.LoadWavesForMap(m.ID)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("ERROR: wave editor: %v", err)
+		we.loadErr = err
+		we.Status = "Cannot edit: " + err.Error()
+		return we
+	}
+	if err != nil {
+		log.Printf("DEBUG: wave editor: %v, starting with one wave", err)
+		we.Waves = []waves.WaveDef{{Wave: 1, Groups: []waves.SpawnGroupDef{we.newGroup()}}}
+		we.Dirty = true
+		we.Status = "This map has no waves yet"
+		return we
+	}
+	we.Waves = defs
+	return we
+}
+
+// newGroup returns a group from the first spawn, of the first enemy type (basic if there is one).
+func (we *WaveEditor) newGroup() waves.SpawnGroupDef {
+	g := waves.SpawnGroupDef{Count: 5, Interval: 1}
+	if len(we.SpawnIDs) > 0 {
+		g.SpawnID = we.SpawnIDs[0]
+	}
+	if _, ok := we.EnemyDB.Enemies["basic"]; ok {
+		g.EnemyType = "basic"
+	} else if len(we.EnemyIDs) > 0 {
+		g.EnemyType = we.EnemyIDs[0]
+	}
+	return g
+}
+
+// Selected returns the selected wave, or nil if there are none.
+func (we *WaveEditor) Selected() *waves.WaveDef {
+	if we.Wave < 0 || we.Wave >= len(we.Waves) {
+		return nil
+	}
+	return &we.Waves[we.Wave]
+}
+
+// SelectWave moves the wave selection by delta.
+func (we *WaveEditor) SelectWave(delta int) {
+	we.Wave = min(max(we.Wave+delta, 0), max(len(we.Waves)-1, 0))
+	we.Group = 0
+}
+
+// SelectGroup moves the group selection within the wave by delta.
+func (we *WaveEditor) SelectGroup(delta int) {
+	if w := we.Selected(); w != nil {
+		we.Group = min(max(we.Group+delta, 0), max(len(w.Groups)-1, 0))
+	}
+}
+
+// SelectField moves the column selection by delta.
+func (we *WaveEditor) SelectField(delta int) {
+	we.Field = min(max(we.Field+delta, 0), fieldCount-1)
+}
+
+// Adjust changes the selected field of the selected group: spawns and enemy types cycle, numbers
+// step up or down.
+func (we *WaveEditor) Adjust(delta int) {
+	w := we.Selected()
+	if w == nil || we.Group >= len(w.Groups) {
+		return
+	}
+	g := &w.Groups[we.Group]
+	switch we.Field {
+	case FieldSpawn:
+		g.SpawnID = cycle(we.SpawnIDs, g.SpawnID, delta)
+	case FieldEnemy:
+		g.EnemyType = cycle(we.EnemyIDs, g.EnemyType, delta)
+	case FieldCount:
+		g.Count = max(g.Count+delta, 1)
+	case FieldInterval:
+		g.Interval = max(roundTenth(g.Interval+0.1*float64(delta)), 0.1)
+	case FieldDelay:
+		g.StartDelay = max(roundTenth(g.StartDelay+0.5*float64(delta)), 0)
+	}
+	we.Dirty = true
+	we.Status = ""
+}
+
+func cycle(ids []string, cur string, delta int) string {
+	if len(ids) == 0 {
+		return cur
+	}
+	i := 0
+	for j, id := range ids {
+		if id == cur {
+			i = j
+			break
+		}
+	}
+	n := len(ids)
+	return ids[((i+delta)%n+n)%n]
+}
+
+func roundTenth(v float64) float64 {
+	return float64(int(v*10+0.5)) / 10
+}
+
+// AddGroup adds a copy of the selected group (or a new group) to the selected wave.
+func (we *WaveEditor) AddGroup() {
+	w := we.Selected()
+	if w == nil {
+		we.AddWave()
+		return
+	}
+	g := we.newGroup()
+	if we.Group < len(w.Groups) {
+		g = w.Groups[we.Group]
+	}
+	w.Groups = append(w.Groups, g)
+	we.Group = len(w.Groups) - 1
+	we.Dirty = true
+	we.Status = "Group added"
+}
+
+// RemoveGroup deletes the selected group; a wave left without groups is deleted too.
+func (we *WaveEditor) RemoveGroup() {
+	w := we.Selected()
+	if w == nil || we.Group >= len(w.Groups) {
+		return
+	}
+	w.Groups = append(w.Groups[:we.Group], w.Groups[we.Group+1:]...)
+	we.Status = "Group removed"
+	if len(w.Groups) == 0 {
+		we.Waves = append(we.Waves[:we.Wave], we.Waves[we.Wave+1:]...)
+		we.renumber()
+		we.Wave = min(we.Wave, max(len(we.Waves)-1, 0))
+		we.Status = "Wave removed"
+	}
+	we.SelectGroup(0)
+	we.Dirty = true
+}
+
+// AddWave inserts a copy of the selected wave after it, as a starting point for a harder one.
+func (we *WaveEditor) AddWave() {
+	wave := waves.WaveDef{Groups: []waves.SpawnGroupDef{we.newGroup()}}
+	if w := we.Selected(); w != nil {
+		wave.Groups = append([]waves.SpawnGroupDef(nil), w.Groups...)
+	}
+	at := min(we.Wave+1, len(we.Waves))
+	we.Waves = append(we.Waves[:at], append([]waves.WaveDef{wave}, we.Waves[at:]...)...)
+	we.renumber()
+	we.Wave, we.Group = at, 0
+	we.Dirty = true
+	we.Status = fmt.Sprintf("Wave %d added", at+1)
+}
+
+// renumber numbers the waves 1, 2, 3, ... in list order.
+func (we *WaveEditor) renumber() {
+	for i := range we.Waves {
+		we.Waves[i].Wave = i + 1
+	}
+}
+
+// SpawnTimes returns when each enemy of g enters the field, in seconds after the wave starts
+// (before difficulty scaling): the first one interval after the start delay, then every interval.
+func SpawnTimes(g waves.SpawnGroupDef) []float64 {
+	times := make([]float64, g.Count)
+	for i := range times {
+		times[i] = g.StartDelay + float64(i+1)*g.Interval
+	}
+	return times
+}
+
+// WaveTotals sums a wave's enemies before difficulty scaling.
+type WaveTotals struct {
+	Enemies  int
+	HP       float64
+	Reward   int
+	Duration float64 // seconds until the last enemy spawns
+}
+
+// Totals returns the totals of wave i.
+func (we *WaveEditor) Totals(i int) WaveTotals {
+	var t WaveTotals
+	for _, g := range we.Waves[i].Groups {
+		t.Enemies += g.Count
+		if def := we.EnemyDB.Get(g.EnemyType); def != nil {
+			t.HP += def.HP * float64(g.Count)
+			t.Reward += def.Reward * g.Count
+		}
+		if times := SpawnTimes(g); len(times) > 0 {
+			t.Duration = max(t.Duration, times[len(times)-1])
+		}
+	}
+	return t
+}
+
+// Save checks the waves the way the game loads them and writes <content dir>/waves/<map id>.json.
+func (we *WaveEditor) Save() (string, error) {
+	if we.loadErr != nil {
+		return "", fmt.Errorf("the waves file for map %q does not load, fix or remove it first: %w", we.MapID, we.loadErr)
+	}
+	if len(we.Waves) == 0 {
+		return "", fmt.Errorf("add at least one wave (N)")
+	}
+	we.renumber()
+	data, err := json.MarshalIndent(struct {
+		Waves []waves.WaveDef `json:"waves"`
+	}{we.Waves}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	defs, err := waves.LoadWavesBytes(data)
+	if err != nil {
+		return "", err
+	}
+	spawnIDs := make(map[string]bool)
+	for _, id := range we.SpawnIDs {
+		spawnIDs[id] = true
+	}
+	if err := waves.ValidateWavesAgainstMap(defs, spawnIDs); err != nil {
+		return "", err
+	}
+	for _, w := range defs {
+		for _, g := range w.Groups {
+			if we.EnemyDB.Get(g.EnemyType) == nil {
+				return "", fmt.Errorf("wave %d uses unknown enemy type %q", w.Wave, g.EnemyType)
+			}
+		}
+	}
+	dir, err := content.Dir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, content.WavesDir, we.MapID+".json")
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", err
+	}
+	we.Dirty = false
+	log.Printf("DEBUG: wave editor saved %d waves for map %q to %s", len(defs), we.MapID, path)
+	return path, nil
+}
@@ -0,0 +1,178 @@
+package effects
+
+import "fmt"
+
+// Kind is a status effect type that towers can apply to enemies on hit.
+type Kind int
+
+const (
+	Slow       Kind = iota // reduces movement speed by Magnitude (0..1); strongest slow wins
+	Burn                   // Magnitude damage per second; reapplying refreshes duration
+	Poison                 // Magnitude damage per second per stack; stacks up to MaxStacks
+	Stun                   // stops movement
+	ArmorShred             // enemy takes Magnitude extra damage fraction per stack
+)
+
+var kindNames = []string{"slow", "burn", "poison", "stun", "armor_shred"}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return "unknown"
+	}
+	return kindNames[k]
+}
+
+// ParseKind parses a JSON effect type.
+func ParseKind(s string) (Kind, error) {
+	for i, name := range kindNames {
+		if name == s {
+			return Kind(i), nil
+		}
+	}
+	return Slow, fmt.Errorf("unknown effect type %q", s)
+}
+
+// Def is the JSON-serializable effect a tower applies on hit.
+type Def struct {
+	Type      string  `json:"type"`
+	Magnitude float64 `json:"magnitude"`
+	Duration  float64 `json:"duration"`
+	MaxStacks int     `json:"max_stacks"` // poison and armor_shred only; 0 = defaultMaxStacks
+}
+
+const defaultMaxStacks = 5
+
+// Validate checks the definition and fills defaults.
+func (d *Def) Validate() error {
+	kind, err := ParseKind(d.Type)
+	if err != nil {
+		return err
+	}
+	if d.Duration <= 0 {
+		return fmt.Errorf("effect %q has invalid duration %f", d.Type, d.Duration)
+	}
+	if d.Magnitude < 0 {
+		return fmt.Errorf("effect %q has invalid magnitude %f", d.Type, d.Magnitude)
+	}
+	if kind == Slow && d.Magnitude > 1 {
+		return fmt.Errorf("slow magnitude %f must be in [0,1]", d.Magnitude)
+	}
+	if d.MaxStacks < 0 {
+		return fmt.Errorf("effect %q has invalid max_stacks %d", d.Type, d.MaxStacks)
+	}
+	if d.MaxStacks == 0 {
+		d.MaxStacks = defaultMaxStacks
+	}
+	return nil
+}
+
+// Status is an effect currently active on an enemy.
+type Status struct {
+	Kind      Kind
+	Magnitude float64
+	Remaining float64
+	Stacks    int
+	MaxStacks int
+}
+
+// List is the set of effects active on one enemy (at most one Status per Kind).
+type List []Status
+
+// Apply adds def to the list. Poison and armor shred gain a stack and refresh; other kinds refresh
+// their duration and keep the strongest magnitude.
+func (l *List) Apply(def Def) {
+	kind, err := ParseKind(def.Type)
+	if err != nil {
+		return
+	}
+	for i := range *l {
+		s := &(*l)[i]
+		if s.Kind != kind {
+			continue
+		}
+		switch kind {
+		case Poison, ArmorShred:
+			if s.Stacks < s.MaxStacks {
+				s.Stacks++
+			}
+			s.Magnitude = def.Magnitude
+		default:
+			if def.Magnitude > s.Magnitude {
+				s.Magnitude = def.Magnitude
+			}
+		}
+		if def.Duration > s.Remaining {
+			s.Remaining = def.Duration
+		}
+		return
+	}
+	*l = append(*l, Status{
+		Kind:      kind,
+		Magnitude: def.Magnitude,
+		Remaining: def.Duration,
+		Stacks:    1,
+		MaxStacks: def.MaxStacks,
+	})
+}
+
+// Update advances durations by dt, drops expired effects and returns damage dealt over dt by burn and poison.
+func (l *List) Update(dt float64) (burn, poison float64) {
+	active := (*l)[:0]
+	for _, s := range *l {
+		tick := dt
+		if s.Remaining < tick {
+			tick = s.Remaining
+		}
+		switch s.Kind {
+		case Burn:
+			burn += s.Magnitude * tick
+		case Poison:
+			poison += s.Magnitude * float64(s.Stacks) * tick
+		}
+		s.Remaining -= dt
+		if s.Remaining > 0 {
+			active = append(active, s)
+		}
+	}
+	*l = active
+	return burn, poison
+}
+
+// Has reports whether an effect of kind k is active.
+func (l List) Has(k Kind) bool {
+	for _, s := range l {
+		if s.Kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+// SpeedFactor returns the movement multiplier from slow and stun (0 when stunned).
+func (l List) SpeedFactor() float64 {
+	factor := 1.0
+	for _, s := range l {
+		switch s.Kind {
+		case Stun:
+			return 0
+		case Slow:
+			factor = 1 - s.Magnitude
+		}
+	}
+	return factor
+}
+
+// DamageTakenMultiplier returns the extra damage multiplier from armor shred.
+func (l List) DamageTakenMultiplier() float64 {
+	for _, s := range l {
+		if s.Kind == ArmorShred {
+			return 1 + s.Magnitude*float64(s.Stacks)
+		}
+	}
+	return 1
+}
+
+// Clear removes all effects.
+func (l *List) Clear() {
+	*l = nil
+}
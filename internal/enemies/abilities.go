@@ -0,0 +1,111 @@
+package enemies
+
+import "fmt"
+
+// Ability types declared in enemies.json.
+const (
+	AbilitySplit      = "split"       // on death, spawn Count Child enemies at the enemy's position
+	AbilitySpawn      = "spawn"       // every Interval seconds, spawn Count Child enemies
+	AbilityHealAura   = "heal_aura"   // heal allies within Radius by Rate HP per second
+	AbilityShieldAura = "shield_aura" // every Interval seconds, give allies within Radius an Amount HP shield
+	AbilityFlying     = "flying"      // ignore walls and the flow field; fly straight to the base
+)
+
+// AbilityDef is the JSON-serializable ability. Only the fields of the chosen type are used.
+type AbilityDef struct {
+	Type     string  `json:"type"`
+	Radius   float64 `json:"radius"`
+	Rate     float64 `json:"rate"`
+	Amount   float64 `json:"amount"`
+	Interval float64 `json:"interval"`
+	Child    string  `json:"child"`
+	Count    int     `json:"count"`
+}
+
+func (a *AbilityDef) validate() error {
+	switch a.Type {
+	case AbilitySplit:
+		if a.Child == "" || a.Count <= 0 {
+			return fmt.Errorf("split ability needs child and positive count")
+		}
+	case AbilitySpawn:
+		if a.Child == "" || a.Count <= 0 {
+			return fmt.Errorf("spawn ability needs child and positive count")
+		}
+		if a.Interval <= 0 {
+			return fmt.Errorf("spawn ability has invalid interval %f", a.Interval)
+		}
+	case AbilityHealAura:
+		if a.Radius <= 0 || a.Rate <= 0 {
+			return fmt.Errorf("heal_aura ability needs positive radius and rate")
+		}
+	case AbilityShieldAura:
+		if a.Radius <= 0 || a.Amount <= 0 {
+			return fmt.Errorf("shield_aura ability needs positive radius and amount")
+		}
+		if a.Interval <= 0 {
+			return fmt.Errorf("shield_aura ability has invalid interval %f", a.Interval)
+		}
+	case AbilityFlying:
+	default:
+		return fmt.Errorf("unknown ability type %q", a.Type)
+	}
+	return nil
+}
+
+// HasAbility reports whether the enemy declares an ability of type t.
+func (d *EnemyDef) HasAbility(t string) bool {
+	for _, a := range d.Abilities {
+		if a.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// allAbilities returns the base abilities followed by those of every phase.
+func (d *EnemyDef) allAbilities() []AbilityDef {
+	var out []AbilityDef
+	out = append(out, d.Abilities...)
+	for _, p := range d.Phases {
+		out = append(out, p.Abilities...)
+	}
+	return out
+}
+
+// ValidateChildren checks that every child type exists and that splitting and spawning cannot
+// recurse forever: no enemy may produce its own type, directly or through its children.
+func (db *EnemyDatabase) ValidateChildren() error {
+	for id, def := range db.Enemies {
+		for _, a := range def.allAbilities() {
+			if a.Child == "" {
+				continue
+			}
+			if _, ok := db.Enemies[a.Child]; !ok {
+				return fmt.Errorf("enemy %q %s ability references unknown child %q", id, a.Type, a.Child)
+			}
+		}
+		if db.producesType(id, id, make(map[string]bool)) {
+			return fmt.Errorf("enemy %q splits into or spawns itself (directly or through its children)", id)
+		}
+	}
+	return nil
+}
+
+// producesType reports whether enemy from (transitively) splits into or spawns target.
+func (db *EnemyDatabase) producesType(from, target string, seen map[string]bool) bool {
+	if seen[from] {
+		return false
+	}
+	seen[from] = true
+	def := db.Enemies[from]
+	for _, a := range def.allAbilities() {
+		if a.Type != AbilitySplit && a.Type != AbilitySpawn {
+			continue
+		}
+		if a.Child == target || db.producesType(a.Child, target, seen) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,17 +1,39 @@
 package enemies
 
 import (
+	"bytes"
 	"embed"
+	"log"
+
+	"terminal-td/internal/content"
 )
 
 //go:embed data/enemies.json
 var defaultEnemiesFS embed.FS
 
-// DefaultEnemies returns the built-in enemy definitions.
+// DefaultEnemies returns the built-in enemy definitions merged with the enemy files from mods and
+// the content directory. A file that fails to load is skipped.
 func DefaultEnemies() (*EnemyDatabase, error) {
 	data, err := defaultEnemiesFS.ReadFile("data/enemies.json")
 	if err != nil {
 		return nil, err
 	}
-	return LoadEnemiesBytes(data)
+	db, err := LoadEnemiesBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range content.Files(content.EnemiesDir) {
+		data, err := f.Read()
+		if err != nil {
+			log.Printf("DEBUG: skipping enemies %s: %v", f.Path, err)
+			continue
+		}
+		n, err := db.Merge(bytes.NewReader(data))
+		if err != nil {
+			log.Printf("DEBUG: skipping enemies %s: %v", f.Path, err)
+			continue
+		}
+		log.Printf("DEBUG: merged %d %s enemies from %s", n, f.Source, f.Path)
+	}
+	return db, nil
 }
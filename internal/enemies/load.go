@@ -6,40 +6,120 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"unicode/utf8"
+
+	"terminal-td/internal/damage"
 )
 
 // LoadEnemies reads enemy definitions from r and returns a database.
 func LoadEnemies(r io.Reader) (*EnemyDatabase, error) {
+	defs, err := loadEnemyDefs(r)
+	if err != nil {
+		return nil, err
+	}
+	db := &EnemyDatabase{Enemies: defs}
+	if err := db.ValidateChildren(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// loadEnemyDefs decodes and validates each definition in r; references between enemies are
+// left to ValidateChildren since they may point at enemies from another file.
+func loadEnemyDefs(r io.Reader) (map[string]EnemyDef, error) {
 	var defs struct {
 		Enemies []EnemyDef `json:"enemies"`
 	}
 	if err := json.NewDecoder(r).Decode(&defs); err != nil {
 		return nil, fmt.Errorf("enemy decode: %w", err)
 	}
-	db := &EnemyDatabase{
-		Enemies: make(map[string]EnemyDef),
-	}
+	byID := make(map[string]EnemyDef)
 	for _, def := range defs.Enemies {
-		if def.ID == "" {
-			return nil, fmt.Errorf("enemy with empty id")
-		}
-		if def.HP <= 0 {
-			return nil, fmt.Errorf("enemy %q has invalid hp %f", def.ID, def.HP)
-		}
-		if def.Speed <= 0 {
-			return nil, fmt.Errorf("enemy %q has invalid speed %f", def.ID, def.Speed)
+		if err := def.Validate(); err != nil {
+			return nil, err
 		}
-		if def.Size <= 0 {
-			return nil, fmt.Errorf("enemy %q has invalid size %d", def.ID, def.Size)
-		}
-		if _, ok := db.Enemies[def.ID]; ok {
+		if _, ok := byID[def.ID]; ok {
 			return nil, fmt.Errorf("duplicate enemy id %q", def.ID)
 		}
-		db.Enemies[def.ID] = def
+		byID[def.ID] = def
 		log.Printf("loaded enemy: id=%q name=%q hp=%.1f speed=%.1f size=%d reward=%d",
 			def.ID, def.Name, def.HP, def.Speed, def.Size, def.Reward)
 	}
-	return db, nil
+	return byID, nil
+}
+
+// Merge adds the enemies in r to db, replacing enemies with the same ID. Children may refer to
+// enemies already in db. On error db is left unchanged.
+func (db *EnemyDatabase) Merge(r io.Reader) (int, error) {
+	defs, err := loadEnemyDefs(r)
+	if err != nil {
+		return 0, err
+	}
+	merged := &EnemyDatabase{Enemies: make(map[string]EnemyDef, len(db.Enemies)+len(defs))}
+	for id, def := range db.Enemies {
+		merged.Enemies[id] = def
+	}
+	for id, def := range defs {
+		merged.Enemies[id] = def
+	}
+	if err := merged.ValidateChildren(); err != nil {
+		return 0, err
+	}
+	db.Enemies = merged.Enemies
+	return len(defs), nil
+}
+
+// Validate checks a single enemy definition and fills in defaults (symbol, color, phase speed).
+// References to other enemies are checked by ValidateChildren once the whole file is loaded.
+func (d *EnemyDef) Validate() error {
+	if d.ID == "" {
+		return fmt.Errorf("enemy with empty id")
+	}
+	if d.HP <= 0 {
+		return fmt.Errorf("enemy %q has invalid hp %f", d.ID, d.HP)
+	}
+	if d.Speed <= 0 {
+		return fmt.Errorf("enemy %q has invalid speed %f", d.ID, d.Speed)
+	}
+	if d.Size <= 0 {
+		return fmt.Errorf("enemy %q has invalid size %d", d.ID, d.Size)
+	}
+	if d.Symbol == "" {
+		d.Symbol = DefaultSymbol
+	}
+	if utf8.RuneCountInString(d.Symbol) != 1 {
+		return fmt.Errorf("enemy %q symbol must be a single character, got %q", d.ID, d.Symbol)
+	}
+	if d.Color == 0 {
+		d.Color = DefaultColor
+	}
+	if d.Color < 0 || d.Color > 255 {
+		return fmt.Errorf("enemy %q has invalid color %d", d.ID, d.Color)
+	}
+	if d.Armor < 0 {
+		return fmt.Errorf("enemy %q has invalid armor %f", d.ID, d.Armor)
+	}
+	for name, r := range d.Resistances {
+		t, err := damage.Parse(name)
+		if err != nil || name == "" {
+			return fmt.Errorf("enemy %q resistance: unknown damage type %q", d.ID, name)
+		}
+		if t == damage.True {
+			return fmt.Errorf("enemy %q cannot resist true damage", d.ID)
+		}
+		if r < -1 || r > damage.MaxResistance {
+			return fmt.Errorf("enemy %q %s resistance %f must be in [-1,%.1f]", d.ID, name, r, damage.MaxResistance)
+		}
+	}
+	for i := range d.Abilities {
+		if err := d.Abilities[i].validate(); err != nil {
+			return fmt.Errorf("enemy %q ability %d: %w", d.ID, i, err)
+		}
+	}
+	if err := d.validatePhases(); err != nil {
+		return fmt.Errorf("enemy %q %w", d.ID, err)
+	}
+	return nil
 }
 
 // LoadEnemiesBytes parses enemy JSON from bytes (for embed or tests).
@@ -0,0 +1,37 @@
+package enemies
+
+import "fmt"
+
+// PhaseDef is a boss phase entered once HP drops to HPThreshold (a fraction of max HP).
+type PhaseDef struct {
+	Name            string  `json:"name"`
+	HPThreshold     float64 `json:"hp_threshold"`
+	SpeedMultiplier float64 `json:"speed_multiplier"` // applied to the enemy's current speed; 0 means unchanged
+
+	// Abilities replaces the enemy's abilities when present in JSON (an empty list removes them all).
+	Abilities []AbilityDef `json:"abilities"`
+}
+
+// validatePhases checks phase thresholds are in (0,1) and strictly decreasing.
+func (d *EnemyDef) validatePhases() error {
+	prev := 1.0
+	for i := range d.Phases {
+		p := &d.Phases[i]
+		if p.HPThreshold <= 0 || p.HPThreshold >= prev {
+			return fmt.Errorf("phase %d hp_threshold %f must be in (0,%g)", i, p.HPThreshold, prev)
+		}
+		prev = p.HPThreshold
+		if p.SpeedMultiplier < 0 {
+			return fmt.Errorf("phase %d has invalid speed_multiplier %f", i, p.SpeedMultiplier)
+		}
+		if p.SpeedMultiplier == 0 {
+			p.SpeedMultiplier = 1
+		}
+		for j := range p.Abilities {
+			if err := p.Abilities[j].validate(); err != nil {
+				return fmt.Errorf("phase %d ability %d: %w", i, j, err)
+			}
+		}
+	}
+	return nil
+}
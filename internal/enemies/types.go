@@ -1,5 +1,17 @@
 package enemies
 
+import (
+	"unicode/utf8"
+
+	"terminal-td/internal/damage"
+)
+
+// DefaultSymbol and DefaultColor are used when an enemy definition omits them.
+const (
+	DefaultSymbol = "M"
+	DefaultColor  = 9 // bright red
+)
+
 type EnemyDef struct {
 	ID     string  `json:"id"`
 	Name   string  `json:"name"`
@@ -7,6 +19,32 @@ type EnemyDef struct {
 	Speed  float64 `json:"speed"`
 	Size   int     `json:"size"`
 	Reward int     `json:"reward"`
+	Symbol string  `json:"symbol"` // single character drawn on the map
+	Color  int     `json:"color"`  // terminal palette color at full HP
+
+	Armor       float64            `json:"armor"`       // flat physical damage reduction per hit
+	Resistances map[string]float64 `json:"resistances"` // damage type -> fraction ignored (negative = weakness)
+
+	Abilities []AbilityDef `json:"abilities"`
+	Phases    []PhaseDef   `json:"phases"` // boss phases in order of decreasing hp_threshold
+}
+
+// Rune returns the glyph used to draw the enemy.
+func (d *EnemyDef) Rune() rune {
+	r, _ := utf8.DecodeRuneInString(d.Symbol)
+	return r
+}
+
+// Defense returns the enemy's armor and resistances for damage resolution.
+func (d *EnemyDef) Defense() damage.Defense {
+	def := damage.Defense{Armor: d.Armor}
+	if len(d.Resistances) > 0 {
+		def.Resistances = make(map[damage.Type]float64, len(d.Resistances))
+		for name, r := range d.Resistances {
+			def.Resistances[damage.Type(name)] = r
+		}
+	}
+	return def
 }
 
 // EnemyDatabase holds all loaded enemy definitions.
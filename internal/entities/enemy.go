@@ -2,6 +2,10 @@ package entities
 
 import (
 	"math"
+
+	"terminal-td/internal/damage"
+	"terminal-td/internal/effects"
+	"terminal-td/internal/enemies"
 	mapdata "terminal-td/internal/map"
 )
 
@@ -17,12 +21,42 @@ type Enemy struct {
 
 	Reward      int
 	EnemyTypeID string
+	Symbol      rune
+	Color       int // terminal palette color at full HP
+	Size        int // footprint in tiles (size×size, anchored at X,Y extending right and down)
+
+	// FieldSize is the footprint the enemy pathfinds with: Size when a wide enough route exists, else 1 (squeezing).
+	FieldSize int
 
 	ReachedBase bool
+
+	WaveIndex int    // index of the wave the enemy belongs to (-1 outside data-driven waves)
+	SpawnID   string // spawn point the enemy (or its parent) entered from
+
+	Defense damage.Defense
+	Effects effects.List
+	Terrain float64 // speed factor of the tile underfoot (mud, ice); 0 counts as 1
+
+	Abilities []AbilityState
+	Flying    bool    // ignores walls and the flow field
+	Shield    float64 // absorbs damage before HP
+
+	Boss   bool
+	Phases []enemies.PhaseDef
+	Phase  int // number of phases entered so far
+}
+
+// AbilityState is a declared ability plus its running timer.
+type AbilityState struct {
+	Def   enemies.AbilityDef
+	Timer float64
 }
 
 const reachedBaseDist = 0.5
 
+// baseHitRadius is the projectile hit distance for a size 1 enemy.
+const baseHitRadius = 0.8
+
 // NewEnemy creates a basic enemy (legacy compatibility).
 func NewEnemy(path mapdata.Path) *Enemy {
 	start := path.Points[0]
@@ -36,23 +70,86 @@ func NewEnemy(path mapdata.Path) *Enemy {
 		Path:        path,
 		Reward:      10,
 		EnemyTypeID: "basic",
+		WaveIndex:   -1,
+		Symbol:      'M',
+		Color:       enemies.DefaultColor,
+		Size:        1,
+		FieldSize:   1,
 	}
 }
 
 // NewEnemyFromDef creates an enemy from a definition and path.
-func NewEnemyFromDef(hp, speed float64, reward int, enemyTypeID string, path mapdata.Path) *Enemy {
+func NewEnemyFromDef(def *enemies.EnemyDef, path mapdata.Path) *Enemy {
 	start := path.Points[0]
 
 	return &Enemy{
 		X:           float64(start.X),
 		Y:           float64(start.Y),
-		Speed:       speed,
-		HP:          hp,
-		MaxHP:       hp,
+		Speed:       def.Speed,
+		HP:          def.HP,
+		MaxHP:       def.HP,
 		Path:        path,
-		Reward:      reward,
-		EnemyTypeID: enemyTypeID,
+		Reward:      def.Reward,
+		EnemyTypeID: def.ID,
+		WaveIndex:   -1,
+		Symbol:      def.Rune(),
+		Color:       def.Color,
+		Size:        max(def.Size, 1),
+		FieldSize:   max(def.Size, 1),
+		Defense:     def.Defense(),
+		Abilities:   newAbilityStates(def.Abilities),
+		Flying:      def.HasAbility(enemies.AbilityFlying),
+		Phases:      def.Phases,
+	}
+}
+
+func newAbilityStates(defs []enemies.AbilityDef) []AbilityState {
+	if len(defs) == 0 {
+		return nil
+	}
+	out := make([]AbilityState, len(defs))
+	for i, d := range defs {
+		out[i] = AbilityState{Def: d}
 	}
+	return out
+}
+
+// Extent is the distance from the anchor to the footprint's center along each axis
+// (0 for size 1); splash and pierce hits are widened by it.
+func (e *Enemy) Extent() float64 {
+	return float64(e.Size-1) / 2
+}
+
+// Center returns the middle of the enemy's footprint (used for targeting and hit detection).
+func (e *Enemy) Center() (x, y float64) {
+	ext := e.Extent()
+	return e.X + ext, e.Y + ext
+}
+
+// HitRadius is how close a projectile must get to Center to hit; it grows with Size.
+func (e *Enemy) HitRadius() float64 {
+	return baseHitRadius + e.Extent()
+}
+
+// Covers reports whether the tile (x, y) lies within the enemy's footprint.
+func (e *Enemy) Covers(x, y int) bool {
+	ex, ey := int(e.X), int(e.Y)
+	size := max(e.Size, 1)
+	return x >= ex && x < ex+size && y >= ey && y < ey+size
+}
+
+// LeakDamage is the base HP removed when this enemy reaches the base.
+func (e *Enemy) LeakDamage() int {
+	return max(e.Size, 1)
+}
+
+// CurrentSpeed returns Speed after slow and stun effects and terrain.
+func (e *Enemy) CurrentSpeed() float64 {
+	speed := e.Speed * e.Effects.SpeedFactor()
+	if e.Terrain > 0 {
+		speed *= e.Terrain
+	}
+	return speed
 }
 
 // Update moves the enemy along waypoints (legacy path-based).
@@ -76,7 +173,7 @@ func (e *Enemy) Update(dt float64) {
 		return
 	}
 
-	moveDist := e.Speed * dt
+	moveDist := e.CurrentSpeed() * dt
 
 	if moveDist > dist {
 		moveDist = dist
@@ -86,10 +183,68 @@ func (e *Enemy) Update(dt float64) {
 	e.Y += (dy / dist) * moveDist
 }
 
+// PhaseName returns the name of the current boss phase ("" before the first phase).
+func (e *Enemy) PhaseName() string {
+	if e.Phase == 0 {
+		return ""
+	}
+	return e.Phases[e.Phase-1].Name
+}
+
+// AdvancePhase enters every phase whose HP threshold has been crossed, applying its speed
+// multiplier and ability set. It returns true if at least one phase was entered.
+func (e *Enemy) AdvancePhase() bool {
+	entered := false
+	for e.Phase < len(e.Phases) && e.HP > 0 && e.HP <= e.Phases[e.Phase].HPThreshold*e.MaxHP {
+		p := e.Phases[e.Phase]
+		e.Speed *= p.SpeedMultiplier
+		if p.Abilities != nil {
+			e.Abilities = newAbilityStates(p.Abilities)
+			e.Flying = false
+			for _, a := range p.Abilities {
+				if a.Type == enemies.AbilityFlying {
+					e.Flying = true
+				}
+			}
+		}
+		e.Phase++
+		entered = true
+	}
+	return entered
+}
+
+// RestorePhase puts a freshly created enemy into phase n, applying the ability set of the
+// latest phase that has one. Speed is left alone because the saved speed already includes it.
+func (e *Enemy) RestorePhase(n int) {
+	for e.Phase < n && e.Phase < len(e.Phases) {
+		p := e.Phases[e.Phase]
+		if p.Abilities != nil {
+			e.Abilities = newAbilityStates(p.Abilities)
+		}
+		e.Phase++
+	}
+}
+
+// UpdateFlying moves the enemy in a straight line toward (baseX, baseY), setting ReachedBase on arrival.
+func (e *Enemy) UpdateFlying(dt float64, baseX, baseY int) {
+	dx := float64(baseX) - e.X
+	dy := float64(baseY) - e.Y
+	dist := math.Sqrt(dx*dx + dy*dy)
+	moveDist := e.CurrentSpeed() * dt
+	if dist <= reachedBaseDist || moveDist >= dist {
+		e.X = float64(baseX)
+		e.Y = float64(baseY)
+		e.ReachedBase = true
+		return
+	}
+	e.X += (dx / dist) * moveDist
+	e.Y += (dy / dist) * moveDist
+}
+
 // UpdateFlow moves the enemy along the flow field direction (no path memory).
 // Caller should set ReachedBase when distance from flow field is < reachedBaseDist.
 func (e *Enemy) UpdateFlow(dt float64, dirX, dirY float64) {
-	moveDist := e.Speed * dt
+	moveDist := e.CurrentSpeed() * dt
 	e.X += dirX * moveDist
 	e.Y += dirY * moveDist
 }
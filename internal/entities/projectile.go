@@ -2,24 +2,48 @@ package entities
 
 import (
 	"math"
+
+	"terminal-td/internal/damage"
+	"terminal-td/internal/effects"
+	"terminal-td/internal/towers"
 )
 
 type Projectile struct {
 	X, Y        float64
+	PrevX       float64
+	PrevY       float64
 	TargetX     float64
 	TargetY     float64
 	TargetEnemy *Enemy
 	Speed       float64
 	Damage      float64
+	DamageType  damage.Type
 	HasHit      bool
+	Source      *Tower // tower that fired it, credited with its damage
+
+	Kind    towers.ProjectileKind
+	Spec    towers.ProjectileDef
+	Effects []effects.Def // applied to each enemy this projectile damages
+
+	// Pierce bolts fly in a straight line until MaxDistance.
+	DirX, DirY  float64
+	Travelled   float64
+	MaxDistance float64
+
+	// HitEnemies are enemies already damaged by this bolt (pierce) or chain (chain).
+	HitEnemies []*Enemy
+	JumpsLeft  int
 }
 
 func NewProjectile(startX, startY float64, targetEnemy *Enemy, speed, damage float64) *Projectile {
+	tx, ty := targetEnemy.Center()
 	return &Projectile{
 		X:           startX,
 		Y:           startY,
-		TargetX:     targetEnemy.X,
-		TargetY:     targetEnemy.Y,
+		PrevX:       startX,
+		PrevY:       startY,
+		TargetX:     tx,
+		TargetY:     ty,
 		TargetEnemy: targetEnemy,
 		Speed:       speed,
 		Damage:      damage,
@@ -27,24 +51,94 @@ func NewProjectile(startX, startY float64, targetEnemy *Enemy, speed, damage flo
 	}
 }
 
+// NewPierceProjectile creates a bolt flying from start toward (towardX, towardY) for maxDistance tiles.
+func NewPierceProjectile(startX, startY, towardX, towardY, speed, damage, maxDistance float64, spec towers.ProjectileDef) *Projectile {
+	dx := towardX - startX
+	dy := towardY - startY
+	dist := math.Sqrt(dx*dx + dy*dy)
+	if dist < 1e-9 {
+		dx, dy, dist = 1, 0, 1
+	}
+	return &Projectile{
+		X:           startX,
+		Y:           startY,
+		PrevX:       startX,
+		PrevY:       startY,
+		TargetX:     towardX,
+		TargetY:     towardY,
+		Speed:       speed,
+		Damage:      damage,
+		Kind:        towers.ProjectilePierce,
+		Spec:        spec,
+		DirX:        dx / dist,
+		DirY:        dy / dist,
+		MaxDistance: maxDistance,
+	}
+}
+
+// HasHitEnemy reports whether e was already damaged by this projectile (or its chain).
+func (p *Projectile) HasHitEnemy(e *Enemy) bool {
+	for _, h := range p.HitEnemies {
+		if h == e {
+			return true
+		}
+	}
+	return false
+}
+
+// SweptDistanceTo returns the distance from (x,y) to the segment the projectile moved along in its last Update.
+func (p *Projectile) SweptDistanceTo(x, y float64) float64 {
+	sx := p.X - p.PrevX
+	sy := p.Y - p.PrevY
+	lenSq := sx*sx + sy*sy
+	t := 0.0
+	if lenSq > 1e-12 {
+		t = ((x-p.PrevX)*sx + (y-p.PrevY)*sy) / lenSq
+		t = math.Max(0, math.Min(1, t))
+	}
+	dx := p.PrevX + t*sx - x
+	dy := p.PrevY + t*sy - y
+	return math.Sqrt(dx*dx + dy*dy)
+}
+
 func (p *Projectile) Update(dt float64) {
 	if p.HasHit {
 		return
 	}
 
-	if p.TargetEnemy == nil || p.TargetEnemy.HP <= 0 {
+	p.PrevX = p.X
+	p.PrevY = p.Y
+
+	if p.Kind == towers.ProjectilePierce {
+		moveDist := p.Speed * dt
+		if p.Travelled+moveDist >= p.MaxDistance {
+			moveDist = p.MaxDistance - p.Travelled
+			p.HasHit = true
+		}
+		p.X += p.DirX * moveDist
+		p.Y += p.DirY * moveDist
+		p.Travelled += moveDist
+		return
+	}
+
+	targetAlive := p.TargetEnemy != nil && p.TargetEnemy.HP > 0
+	if !targetAlive && p.Kind != towers.ProjectileSplash {
 		p.HasHit = true
 		return
 	}
 
-	p.TargetX = p.TargetEnemy.X
-	p.TargetY = p.TargetEnemy.Y
+	// Splash shells keep flying to the last known position when their target dies.
+	hitDist := baseHitRadius
+	if targetAlive {
+		p.TargetX, p.TargetY = p.TargetEnemy.Center()
+		hitDist = p.TargetEnemy.HitRadius()
+	}
 
 	dx := p.TargetX - p.X
 	dy := p.TargetY - p.Y
 	dist := math.Sqrt(dx*dx + dy*dy)
 
-	if dist < 0.8 {
+	if dist < hitDist {
 		p.X = p.TargetX
 		p.Y = p.TargetY
 		p.HasHit = true
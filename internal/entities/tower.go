@@ -2,49 +2,80 @@ package entities
 
 import (
 	"math"
+
+	"terminal-td/internal/damage"
+	"terminal-td/internal/effects"
+	"terminal-td/internal/towers"
 )
 
 type Tower struct {
-	X, Y int
-	Type TowerType
+	X, Y   int
+	TypeID string
+
+	Range           float64
+	Damage          float64
+	DamageType      damage.Type
+	FireRate        float64
+	ProjectileSpeed float64
+	Projectile      towers.ProjectileDef
+	ProjectileKind  towers.ProjectileKind
+	Effects         []effects.Def
+	Cost            int
 
-	Range    float64
-	Damage   float64
-	FireRate float64
-	Cost     int
+	Level    int    // upgrades bought (0 = base stats)
+	Branch   string // chosen upgrade branch ID, empty until one is bought
+	Invested int    // total money spent on this tower (placement + upgrades)
 
-	Target   *Enemy
-	Cooldown float64
+	Target      *Enemy
+	Cooldown    float64
+	DamageDealt float64 // HP removed from enemies by this tower's projectiles
+	Targeting   towers.TargetingMode
+	Retarget    towers.RetargetPolicy
 
 	Symbol rune
 	Color  int
 }
 
-type TowerType int
-
-const (
-	TowerBasic TowerType = iota
-)
-
-func NewTower(x, y int, towerType TowerType) *Tower {
-	t := &Tower{
-		X:        x,
-		Y:        y,
-		Type:     towerType,
-		Cooldown: 0,
+// NewTower creates a tower at (x, y) from a tower definition.
+func NewTower(x, y int, def *towers.TowerDef) *Tower {
+	targeting, _ := towers.ParseTargetingMode(def.Targeting)
+	retarget, _ := towers.ParseRetargetPolicy(def.Retarget)
+	kind, _ := towers.ParseProjectileKind(def.Projectile.Kind)
+	damageType, _ := damage.Parse(def.DamageType)
+	return &Tower{
+		X:               x,
+		Y:               y,
+		TypeID:          def.ID,
+		Range:           def.Range,
+		Damage:          def.Damage,
+		DamageType:      damageType,
+		FireRate:        def.FireRate,
+		ProjectileSpeed: def.ProjectileSpeed,
+		Projectile:      def.Projectile,
+		ProjectileKind:  kind,
+		Effects:         def.Effects,
+		Cost:            def.Cost,
+		Invested:        def.Cost,
+		Cooldown:        0,
+		Targeting:       targeting,
+		Retarget:        retarget,
+		Symbol:          def.Rune(),
+		Color:           def.Color,
 	}
+}
 
-	switch towerType {
-	case TowerBasic:
-		t.Range = 5.0
-		t.Damage = 10.0
-		t.FireRate = 1.0
-		t.Cost = 50
-		t.Symbol = 'T'
-		t.Color = 3
-	}
+const minFireRate = 0.05
 
-	return t
+// ApplyUpgrade adds the upgrade's stat changes and records the purchase.
+func (t *Tower) ApplyUpgrade(opt towers.UpgradeOption) {
+	t.Range = math.Max(1, t.Range+opt.Def.Range)
+	t.Damage = math.Max(0, t.Damage+opt.Def.Damage)
+	t.FireRate = math.Max(minFireRate, t.FireRate+opt.Def.FireRate)
+	t.Level = opt.Level
+	if opt.BranchID != "" {
+		t.Branch = opt.BranchID
+	}
+	t.Invested += opt.Cost
 }
 
 func (t *Tower) DistanceTo(x, y float64) float64 {
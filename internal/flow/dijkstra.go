@@ -0,0 +1,171 @@
+package flow
+
+import (
+	"container/heap"
+	"math"
+)
+
+// Options tune how a field is computed. The zero value gives the unit-cost, 4-neighbour field.
+type Options struct {
+	// Costs [y][x] is the cost of crossing each tile; nil means 1 everywhere. Costs must be
+	// positive. A step between two tiles costs the mean of their costs times the step length.
+	Costs [][]float64
+	// Diagonal also allows diagonal steps, but only when both tiles beside the step are walkable
+	// so enemies never cut the corner of a blocked tile.
+	Diagonal bool
+}
+
+// neighbours are the step offsets: up, down, left, right, then the diagonals. When several steps
+// are equally good the first one wins, so fields are deterministic.
+var neighbours = [8][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}, {-1, -1}, {1, -1}, {-1, 1}, {1, 1}}
+
+func (o Options) cost(x, y int) float64 {
+	if o.Costs == nil {
+		return 1
+	}
+	return o.Costs[y][x]
+}
+
+// step returns the tile reached from (x, y) by neighbour i and what the step costs, or ok=false
+// when the step leaves the grid, ends on an unwalkable tile or cuts a corner.
+func (o Options) step(walkable [][]bool, x, y, i int) (nx, ny int, cost float64, ok bool) {
+	dx, dy := neighbours[i][0], neighbours[i][1]
+	nx, ny = x+dx, y+dy
+	if ny < 0 || ny >= len(walkable) || nx < 0 || nx >= len(walkable[ny]) || !walkable[ny][nx] {
+		return 0, 0, 0, false
+	}
+	length := 1.0
+	if dx != 0 && dy != 0 {
+		if !walkable[y][nx] || !walkable[ny][x] {
+			return 0, 0, 0, false
+		}
+		length = math.Sqrt2
+	}
+	return nx, ny, (o.cost(x, y) + o.cost(nx, ny)) / 2 * length, true
+}
+
+func (o Options) neighbourCount() int {
+	if o.Diagonal {
+		return 8
+	}
+	return 4
+}
+
+type queueItem struct {
+	x, y int
+	dist float64
+}
+
+// queue is a min-heap of tiles by distance.
+type queue []queueItem
+
+func (q queue) Len() int           { return len(q) }
+func (q queue) Less(i, j int) bool { return q[i].dist < q[j].dist }
+func (q queue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *queue) Push(x any)        { *q = append(*q, x.(queueItem)) }
+func (q *queue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// ComputeDistances fills field.Distances with the unit-cost, 4-neighbour distance to the base.
+func ComputeDistances(field *Field, walkable [][]bool, baseX, baseY int) {
+	ComputeDistancesWithOptions(field, walkable, baseX, baseY, Options{})
+}
+
+// ComputeDistancesWithOptions fills field.Distances with the cheapest cost of reaching the base
+// from each tile (Dijkstra from the base outward). Unreachable tiles get Inf.
+func ComputeDistancesWithOptions(field *Field, walkable [][]bool, baseX, baseY int, opts Options) {
+	for y := 0; y < field.Height; y++ {
+		for x := 0; x < field.Width; x++ {
+			field.Distances[y][x] = Inf
+		}
+	}
+	if baseX < 0 || baseX >= field.Width || baseY < 0 || baseY >= field.Height {
+		return
+	}
+	if !walkable[baseY][baseX] {
+		return
+	}
+
+	field.Distances[baseY][baseX] = 0
+	q := &queue{{baseX, baseY, 0}}
+	n := opts.neighbourCount()
+	for q.Len() > 0 {
+		c := heap.Pop(q).(queueItem)
+		if c.dist > field.Distances[c.y][c.x] {
+			continue // stale entry
+		}
+		for i := 0; i < n; i++ {
+			nx, ny, cost, ok := opts.step(walkable, c.x, c.y, i)
+			if !ok {
+				continue
+			}
+			if newDist := c.dist + cost; newDist < field.Distances[ny][nx] {
+				field.Distances[ny][nx] = newDist
+				heap.Push(q, queueItem{nx, ny, newDist})
+			}
+		}
+	}
+}
+
+// ComputeDirections points each walkable tile at its unit-cost, 4-neighbour next step.
+func ComputeDirections(field *Field, walkable [][]bool) {
+	ComputeDirectionsWithOptions(field, walkable, Options{})
+}
+
+// ComputeDirectionsWithOptions points each walkable tile at the neighbour its cheapest route to
+// the base goes through. The base and unreachable tiles get a zero direction.
+func ComputeDirectionsWithOptions(field *Field, walkable [][]bool, opts Options) {
+	n := opts.neighbourCount()
+	for y := 0; y < field.Height; y++ {
+		for x := 0; x < field.Width; x++ {
+			field.Directions[y][x] = Vec2{}
+			if !walkable[y][x] {
+				continue
+			}
+			cur := field.Distances[y][x]
+			bestNx, bestNy := -1, -1
+			best := Inf
+
+			for i := 0; i < n; i++ {
+				nx, ny, cost, ok := opts.step(walkable, x, y, i)
+				if !ok {
+					continue
+				}
+				d := field.Distances[ny][nx]
+				if d >= cur {
+					continue
+				}
+				if d+cost < best {
+					best = d + cost
+					bestNx, bestNy = nx, ny
+				}
+			}
+
+			if bestNx < 0 {
+				continue
+			}
+			dir := Vec2{
+				X: float64(bestNx - x),
+				Y: float64(bestNy - y),
+			}
+			field.Directions[y][x] = dir.Normalize()
+		}
+	}
+}
+
+// Compute builds the unit-cost, 4-neighbour field toward the base.
+func Compute(width, height int, walkable [][]bool, baseX, baseY int) *Field {
+	return ComputeWithOptions(width, height, walkable, baseX, baseY, Options{})
+}
+
+// ComputeWithOptions builds a field toward the base using opts' tile costs and movement rules.
+func ComputeWithOptions(width, height int, walkable [][]bool, baseX, baseY int, opts Options) *Field {
+	field := NewField(width, height)
+	ComputeDistancesWithOptions(field, walkable, baseX, baseY, opts)
+	ComputeDirectionsWithOptions(field, walkable, opts)
+	return field
+}
@@ -2,16 +2,53 @@ package flow
 
 import mapdata "terminal-td/internal/map"
 
-// IsWalkable returns true only for path, spawn, and base tiles.
+// IsWalkable returns true for path, spawn and base tiles and walkable terrain (mud, ice).
 func IsWalkable(tile mapdata.TileType) bool {
-	return tile == mapdata.PathTile || tile == mapdata.SpawnTile || tile == mapdata.BaseTile
+	return tile.Walkable()
 }
 
-// BuildWalkability returns a mask [y][x] where true = path/spawn/base. No blocking.
+// BuildWalkability returns a mask [y][x] where true = walkable tile. No blocking.
 func BuildWalkability(grid *mapdata.Grid) [][]bool {
 	return BuildWalkabilityWithBlocked(grid, nil)
 }
 
+// ErodeForSize returns a mask where a tile is walkable only if the size×size square anchored at it
+// (tile plus size-1 tiles right and down) is fully walkable. The goal tile (keepX, keepY) stays walkable
+// when it was walkable in the input so large enemies can still finish their route.
+func ErodeForSize(walkable [][]bool, size, keepX, keepY int) [][]bool {
+	height := len(walkable)
+	if height == 0 || size <= 1 {
+		return walkable
+	}
+	width := len(walkable[0])
+	clearance := make([][]int, height+1)
+	for y := range clearance {
+		clearance[y] = make([]int, width+1)
+	}
+	out := make([][]bool, height)
+	for y := height - 1; y >= 0; y-- {
+		out[y] = make([]bool, width)
+		for x := width - 1; x >= 0; x-- {
+			if !walkable[y][x] {
+				continue
+			}
+			c := clearance[y+1][x]
+			if clearance[y][x+1] < c {
+				c = clearance[y][x+1]
+			}
+			if clearance[y+1][x+1] < c {
+				c = clearance[y+1][x+1]
+			}
+			clearance[y][x] = c + 1
+			out[y][x] = clearance[y][x] >= size
+		}
+	}
+	if keepY >= 0 && keepY < height && keepX >= 0 && keepX < width && walkable[keepY][keepX] {
+		out[keepY][keepX] = true
+	}
+	return out
+}
+
 // BuildWalkabilityWithBlocked returns walkable mask; path tiles in blocked set are not walkable.
 func BuildWalkabilityWithBlocked(grid *mapdata.Grid, blockedTiles [][2]int) [][]bool {
 	blocked := make(map[[2]int]bool)
@@ -31,3 +68,16 @@ func BuildWalkabilityWithBlocked(grid *mapdata.Grid, blockedTiles [][2]int) [][]
 	}
 	return w
 }
+
+// TerrainCosts returns the cost [y][x] of crossing each tile: how long it takes compared to a
+// path tile, so mud costs more and ice less.
+func TerrainCosts(grid *mapdata.Grid) [][]float64 {
+	costs := make([][]float64, grid.Height)
+	for y := 0; y < grid.Height; y++ {
+		costs[y] = make([]float64, grid.Width)
+		for x := 0; x < grid.Width; x++ {
+			costs[y][x] = 1 / grid.Tiles[y][x].SpeedFactor()
+		}
+	}
+	return costs
+}
@@ -0,0 +1,106 @@
+package game
+
+import (
+	"log"
+	"math"
+
+	"terminal-td/internal/enemies"
+	"terminal-td/internal/entities"
+)
+
+// updateAbilities runs enemy abilities in g.Enemies order, then in each enemy's declaration order.
+// Minions spawned this step are added afterwards so they first act on the next step.
+func (g *Game) updateAbilities(dt float64) {
+	if !g.Manager.IsSimulationRunning() {
+		return
+	}
+
+	var spawned []*entities.Enemy
+	for _, e := range g.Enemies {
+		if e.HP <= 0 {
+			continue
+		}
+		if e.AdvancePhase() {
+			log.Printf("DEBUG: %s entered phase %d %q (speed %.2f)", e.EnemyTypeID, e.Phase, e.PhaseName(), e.Speed)
+		}
+		for i := range e.Abilities {
+			a := &e.Abilities[i]
+			switch a.Def.Type {
+			case enemies.AbilitySpawn:
+				a.Timer += dt
+				for a.Timer >= a.Def.Interval {
+					a.Timer -= a.Def.Interval
+					spawned = append(spawned, g.childEnemies(e, a.Def.Child, a.Def.Count)...)
+				}
+			case enemies.AbilityHealAura:
+				for _, ally := range g.alliesInRadius(e, a.Def.Radius) {
+					ally.HP = math.Min(ally.MaxHP, ally.HP+a.Def.Rate*dt)
+				}
+			case enemies.AbilityShieldAura:
+				a.Timer += dt
+				if a.Timer >= a.Def.Interval {
+					a.Timer -= a.Def.Interval
+					for _, ally := range g.alliesInRadius(e, a.Def.Radius) {
+						ally.Shield = math.Max(ally.Shield, a.Def.Amount)
+					}
+				}
+			}
+		}
+	}
+
+	for _, c := range spawned {
+		g.addEnemy(c)
+	}
+}
+
+// ActiveBoss returns the first living boss in play, or nil.
+func (g *Game) ActiveBoss() *entities.Enemy {
+	for _, e := range g.Enemies {
+		if e.Boss && e.HP > 0 {
+			return e
+		}
+	}
+	return nil
+}
+
+// alliesInRadius returns the living enemies other than src whose centers are within radius of src's center.
+func (g *Game) alliesInRadius(src *entities.Enemy, radius float64) []*entities.Enemy {
+	sx, sy := src.Center()
+	var out []*entities.Enemy
+	for _, e := range g.Enemies {
+		if e == src || e.HP <= 0 {
+			continue
+		}
+		cx, cy := e.Center()
+		if math.Hypot(cx-sx, cy-sy) <= radius {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// splitChildren returns the enemies a dead enemy splits into (nil when it has no split ability).
+func (g *Game) splitChildren(e *entities.Enemy) []*entities.Enemy {
+	var out []*entities.Enemy
+	for _, a := range e.Abilities {
+		if a.Def.Type == enemies.AbilitySplit {
+			out = append(out, g.childEnemies(e, a.Def.Child, a.Def.Count)...)
+		}
+	}
+	return out
+}
+
+// childEnemies creates count enemies of type childID at the parent's position, continuing along its path.
+func (g *Game) childEnemies(parent *entities.Enemy, childID string, count int) []*entities.Enemy {
+	out := make([]*entities.Enemy, 0, count)
+	for range count {
+		c := g.newEnemy(childID, parent.Path)
+		c.X, c.Y = parent.X, parent.Y
+		c.PathIndex = parent.PathIndex
+		c.WaveIndex = parent.WaveIndex
+		c.SpawnID = parent.SpawnID
+		out = append(out, c)
+	}
+	log.Printf("DEBUG: %s at (%.1f,%.1f) produced %d %s", parent.EnemyTypeID, parent.X, parent.Y, count, childID)
+	return out
+}
@@ -0,0 +1,47 @@
+package game
+
+import "log"
+
+// earlyCallBonusPerSecond is the money and score paid per second skipped by calling a wave early.
+const earlyCallBonusPerSecond = 10
+
+// CanCallNextWave reports whether the player may start the next wave now: between waves, or
+// during a wave (overlapping it) when another wave remains.
+func (g *Game) CanCallNextWave() bool {
+	if g.Wave == nil {
+		return false
+	}
+	switch g.Manager.State {
+	case StatePreWave:
+		return g.Wave.HasNext()
+	case StateInWave:
+		return len(g.Wave.Active) > 0 && (g.Wave.HasNext() || g.Options.Endless)
+	}
+	return false
+}
+
+// EarlyCallBonus is the bonus for calling the next wave now: proportional to the inter-wave timer
+// left. During a wave no timer is running, so overlapping waves pays nothing.
+func (g *Game) EarlyCallBonus() int {
+	if g.Manager.State != StatePreWave {
+		return 0
+	}
+	return int(max(g.Manager.InterWaveTimer, 0) * earlyCallBonusPerSecond)
+}
+
+// CallNextWave starts the next wave immediately and pays the early-call bonus.
+func (g *Game) CallNextWave() bool {
+	if !g.CanCallNextWave() {
+		log.Println("DEBUG: Cannot call next wave now")
+		return false
+	}
+	bonus := g.EarlyCallBonus()
+	g.Money += bonus
+	g.Score.Points += bonus
+
+	g.Manager.StartWave()
+	g.ensureWave(g.Wave.Next)
+	g.startNextWave()
+	log.Printf("DEBUG: Wave %d called early (+%d), active waves: %d", g.Wave.Next, bonus, len(g.Wave.Active))
+	return true
+}
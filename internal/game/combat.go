@@ -0,0 +1,232 @@
+package game
+
+import (
+	"log"
+	"math"
+
+	"terminal-td/internal/damage"
+	"terminal-td/internal/entities"
+	"terminal-td/internal/towers"
+)
+
+// Damage types of status effect ticks.
+const (
+	burnDamageType   = damage.Magic
+	poisonDamageType = damage.True
+)
+
+// pierceRangeFactor scales tower range into bolt travel distance when max_distance is unset.
+const pierceRangeFactor = 1.5
+
+func (g *Game) fireTower(tower *entities.Tower) {
+	if tower.Target == nil {
+		return
+	}
+
+	var projectile *entities.Projectile
+	switch tower.ProjectileKind {
+	case towers.ProjectilePierce:
+		tx, ty := tower.Target.Center()
+		maxDist := tower.Projectile.MaxDistance
+		if maxDist <= 0 {
+			maxDist = tower.Range * pierceRangeFactor
+		}
+		projectile = entities.NewPierceProjectile(
+			float64(tower.X),
+			float64(tower.Y),
+			tx,
+			ty,
+			tower.ProjectileSpeed,
+			tower.Damage,
+			maxDist,
+			tower.Projectile,
+		)
+	default:
+		projectile = entities.NewProjectile(
+			float64(tower.X),
+			float64(tower.Y),
+			tower.Target,
+			tower.ProjectileSpeed,
+			tower.Damage,
+		)
+		projectile.Kind = tower.ProjectileKind
+		projectile.Spec = tower.Projectile
+		projectile.JumpsLeft = tower.Projectile.Jumps
+	}
+	projectile.DamageType = tower.DamageType
+	projectile.Effects = tower.Effects
+	projectile.Source = tower
+
+	g.Projectiles = append(g.Projectiles, projectile)
+	log.Printf("DEBUG: Tower at (%d, %d) fired %s at enemy (HP: %.1f), Projectiles: %d", tower.X, tower.Y, tower.ProjectileKind, tower.Target.HP, len(g.Projectiles))
+}
+
+func (g *Game) updateProjectiles(dt float64) {
+	active := []*entities.Projectile{}
+	var spawned []*entities.Projectile
+
+	for _, proj := range g.Projectiles {
+		proj.Update(dt)
+
+		switch proj.Kind {
+		case towers.ProjectilePierce:
+			g.applyPierceHits(proj)
+			if !proj.HasHit {
+				active = append(active, proj)
+			}
+			continue
+		case towers.ProjectileSplash:
+			if proj.HasHit {
+				g.explode(proj)
+				continue
+			}
+			active = append(active, proj)
+			continue
+		}
+
+		if proj.HasHit {
+			if proj.TargetEnemy != nil && proj.TargetEnemy.HP > 0 {
+				g.hitEnemy(proj, proj.TargetEnemy, proj.Damage)
+				if proj.Kind == towers.ProjectileChain {
+					if next := g.jumpChain(proj); next != nil {
+						spawned = append(spawned, next)
+					}
+				}
+			}
+			continue
+		}
+		if proj.TargetEnemy != nil && proj.TargetEnemy.HP > 0 {
+			active = append(active, proj)
+		}
+	}
+	g.Projectiles = append(active, spawned...)
+}
+
+// hitEnemy damages e with a projectile hit and applies the projectile's status effects if e survives.
+func (g *Game) hitEnemy(proj *entities.Projectile, e *entities.Enemy, amount float64) {
+	dealt := g.damageEnemy(e, amount, proj.DamageType)
+	if proj.Source != nil {
+		proj.Source.DamageDealt += dealt
+	}
+	if e.HP <= 0 {
+		return
+	}
+	for _, def := range proj.Effects {
+		e.Effects.Apply(def)
+	}
+}
+
+// updateEffects ticks status effects on every enemy and applies burn/poison damage.
+func (g *Game) updateEffects(dt float64) {
+	for _, e := range g.Enemies {
+		if e.HP <= 0 || len(e.Effects) == 0 {
+			continue
+		}
+		burn, poison := e.Effects.Update(dt)
+		if burn > 0 {
+			g.Stats.EffectDamage += g.damageEnemy(e, burn, burnDamageType)
+		}
+		if poison > 0 {
+			g.Stats.EffectDamage += g.damageEnemy(e, poison, poisonDamageType)
+		}
+	}
+}
+
+// damageEnemy resolves raw damage of type t against e's armor/resistances (then armor shred),
+// applies it and pays out the reward if this hit killed it. Every source of enemy damage goes through here.
+// It returns the HP actually removed (shield absorption and overkill excluded).
+func (g *Game) damageEnemy(e *entities.Enemy, raw float64, t damage.Type) float64 {
+	if e.HP <= 0 {
+		return 0
+	}
+	amount := damage.Resolve(raw, t, e.Defense) * e.Effects.DamageTakenMultiplier()
+	if e.Shield > 0 {
+		absorbed := math.Min(e.Shield, amount)
+		e.Shield -= absorbed
+		amount -= absorbed
+	}
+	dealt := math.Min(amount, e.HP)
+	e.HP -= amount
+	if e.HP <= 0 {
+		reward := e.Reward
+		if reward == 0 {
+			reward = 10
+		}
+		reward = g.Difficulty.Reward(reward)
+		g.Money += reward
+		g.Score.Points += reward
+		g.Score.EnemiesKilled++
+	}
+	return dealt
+}
+
+// explode damages every living enemy within the splash radius; damage falls off linearly toward the edge.
+func (g *Game) explode(proj *entities.Projectile) {
+	radius := proj.Spec.Radius
+	hits := 0
+	for _, e := range g.Enemies {
+		if e.HP <= 0 {
+			continue
+		}
+		cx, cy := e.Center()
+		dist := math.Max(0, math.Hypot(cx-proj.X, cy-proj.Y)-e.Extent())
+		if dist > radius {
+			continue
+		}
+		g.hitEnemy(proj, e, proj.Damage*(1-proj.Spec.Falloff*dist/radius))
+		hits++
+	}
+	log.Printf("DEBUG: Splash at (%.1f,%.1f) hit %d enemies", proj.X, proj.Y, hits)
+}
+
+// applyPierceHits damages every enemy the bolt crossed during its last move (each enemy at most once).
+func (g *Game) applyPierceHits(proj *entities.Projectile) {
+	for _, e := range g.Enemies {
+		if e.HP <= 0 || proj.HasHitEnemy(e) {
+			continue
+		}
+		cx, cy := e.Center()
+		if proj.SweptDistanceTo(cx, cy) > proj.Spec.Width+e.Extent() {
+			continue
+		}
+		proj.HitEnemies = append(proj.HitEnemies, e)
+		g.hitEnemy(proj, e, proj.Damage)
+	}
+}
+
+// jumpChain returns a new chain projectile from the hit enemy to the nearest unhit enemy within jump radius, or nil.
+func (g *Game) jumpChain(proj *entities.Projectile) *entities.Projectile {
+	from := proj.TargetEnemy
+	proj.HitEnemies = append(proj.HitEnemies, from)
+	if proj.JumpsLeft <= 0 {
+		return nil
+	}
+
+	var next *entities.Enemy
+	var bestDist float64
+	for _, e := range g.Enemies {
+		if e.HP <= 0 || proj.HasHitEnemy(e) {
+			continue
+		}
+		ex, ey := e.Center()
+		fx, fy := from.Center()
+		dist := math.Hypot(ex-fx, ey-fy)
+		if dist <= proj.Spec.JumpRadius && (next == nil || dist < bestDist) {
+			next = e
+			bestDist = dist
+		}
+	}
+	if next == nil {
+		return nil
+	}
+
+	jump := entities.NewProjectile(proj.X, proj.Y, next, proj.Speed, proj.Damage*proj.Spec.Decay)
+	jump.Kind = towers.ProjectileChain
+	jump.Spec = proj.Spec
+	jump.DamageType = proj.DamageType
+	jump.Effects = proj.Effects
+	jump.Source = proj.Source
+	jump.JumpsLeft = proj.JumpsLeft - 1
+	jump.HitEnemies = proj.HitEnemies
+	return jump
+}
@@ -1,7 +1,85 @@
 package game
 
+import (
+	"log"
+	"math"
+
+	"terminal-td/internal/difficulty"
+)
+
+// Difficulty holds the run's enemy scaling: the chosen preset's multipliers plus the per-wave ramp
+// (SpeedMultiplier, SpawnMultiplier and CountBonus grow every cleared wave).
 type Difficulty struct {
 	SpeedMultiplier float64
 	SpawnMultiplier float64
-	CountBonus      int
+	CountBonus      int // extra enemies per wave, spread over its groups
+
+	HPMultiplier       float64
+	CountMultiplier    float64
+	IntervalMultiplier float64
+	RewardMultiplier   float64
+}
+
+// newDifficulty returns the starting difficulty for a preset.
+func newDifficulty(p difficulty.Preset) Difficulty {
+	return Difficulty{
+		SpeedMultiplier:    p.SpeedMultiplier,
+		SpawnMultiplier:    1.0,
+		CountBonus:         0,
+		HPMultiplier:       p.HPMultiplier,
+		CountMultiplier:    p.CountMultiplier,
+		IntervalMultiplier: p.IntervalMultiplier,
+		RewardMultiplier:   p.RewardMultiplier,
+	}
+}
+
+// GroupCount scales a spawn group's enemy count by the preset.
+func (d Difficulty) GroupCount(count int) int {
+	return max(1, int(math.Round(float64(count)*d.CountMultiplier)))
+}
+
+// GroupBonus is group i's share of the wave's CountBonus when it is spread over n groups, so a
+// wave gets CountBonus extra enemies in total however many groups it has.
+func (d Difficulty) GroupBonus(i, n int) int {
+	if n <= 0 {
+		return 0
+	}
+	bonus := d.CountBonus / n
+	if i < d.CountBonus%n {
+		bonus++
+	}
+	return bonus
+}
+
+// GroupInterval scales a spawn group's interval between enemies.
+func (d Difficulty) GroupInterval(interval float64) float64 {
+	return interval * d.IntervalMultiplier / math.Max(d.SpawnMultiplier, 0.1)
+}
+
+// Reward scales a kill reward (at least 1).
+func (d Difficulty) Reward(reward int) int {
+	return max(1, int(math.Round(float64(reward)*d.RewardMultiplier)))
+}
+
+// loadPreset returns the preset with the given ID, falling back to a neutral preset.
+func loadPreset(id string) difficulty.Preset {
+	db, err := difficulty.DefaultPresets()
+	if err != nil {
+		log.Printf("load difficulty presets: %v, using neutral preset", err)
+		return difficulty.Neutral()
+	}
+	p := db.Get(id)
+	if p == nil {
+		log.Printf("WARN: difficulty %q not found, using %q", id, difficulty.DefaultPresetID)
+		p = db.Get(difficulty.DefaultPresetID)
+		if p == nil {
+			return difficulty.Neutral()
+		}
+	}
+	return *p
+}
+
+// scaledBaseHP returns the map's base HP under the preset (at least 1).
+func (g *Game) scaledBaseHP(hp int) int {
+	return max(1, int(math.Round(float64(hp)*g.Preset.BaseHPMultiplier)))
 }
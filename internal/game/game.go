@@ -3,10 +3,13 @@ package game
 import (
 	"fmt"
 	"log"
+	"math/rand/v2"
+	"terminal-td/internal/difficulty"
 	"terminal-td/internal/enemies"
 	"terminal-td/internal/entities"
 	"terminal-td/internal/flow"
 	mapdata "terminal-td/internal/map"
+	"terminal-td/internal/towers"
 	waves "terminal-td/internal/waves"
 	"time"
 )
@@ -27,20 +30,36 @@ type Game struct {
 
 	Wave       *waves.WaveManager
 	EnemyDB    *enemies.EnemyDatabase
+	TowerDB    *towers.TowerDatabase
 	LegacyWave WaveManager // kept for backward compat during transition
 	Base       Base
 	Speed      float64
 
-	FlowField *flow.Field
-	Walkable  [][]bool
+	FlowField  *flow.Field
+	Walkable   [][]bool
+	SizeFields map[int]*flow.Field // flow fields for enemies larger than one tile, built on demand
+	flowOpts   flow.Options        // tile costs and movement rules of FlowField and SizeFields
 
 	Money int
 
 	Score      Score
 	Difficulty Difficulty
+	Stats      Stats
 
 	Manager *GameManager
 
+	Options   Options
+	Preset    difficulty.Preset
+	Generator *waves.Generator // endless-mode wave generator (nil unless Options.Endless)
+
+	RNG         *rand.Rand // every random decision in the simulation draws from this
+	rngSource   *rand.PCG
+	Tick        uint64  // simulation steps run so far
+	accumulator float64 // scaled time not yet consumed by a step
+
+	Recording *Replay // commands recorded so far (nil when not recording)
+	playback  *playback
+
 	CursorX int
 	CursorY int
 }
@@ -57,9 +76,11 @@ func NewGame() *Game {
 
 // NewGameFromMap builds a Game from a data-driven map (spawns, paths, base from map).
 func NewGameFromMap(m *mapdata.GameMap) *Game {
-	grid := m.Grid
-	path := m.PrimaryPath()
+	return NewGameWithOptions(m, Options{})
+}
 
+// NewGameWithOptions builds a Game from a map with per-run options (e.g. endless mode).
+func NewGameWithOptions(m *mapdata.GameMap, opts Options) *Game {
 	enemyDB, err := enemies.DefaultEnemies()
 	if err != nil {
 		log.Printf("load enemies: %v, using fallback", err)
@@ -76,6 +97,16 @@ func NewGameFromMap(m *mapdata.GameMap) *Game {
 		}
 	}
 
+	return NewGameFromData(m, enemyDB, waveDefs, opts)
+}
+
+// NewGameFromData builds a Game from a map with an explicit enemy catalog and waves instead of
+// the built-in ones (e.g. for balance testing).
+func NewGameFromData(m *mapdata.GameMap, enemyDB *enemies.EnemyDatabase, waveDefs []waves.WaveDef, opts Options) *Game {
+	grid := m.Grid
+	path := m.PrimaryPath()
+	towerDB := loadTowerDB()
+
 	spawnIDs := make(map[string]bool)
 	for _, spawn := range m.Spawns {
 		spawnIDs[spawn.ID] = true
@@ -86,9 +117,6 @@ func NewGameFromMap(m *mapdata.GameMap) *Game {
 
 	waveMgr := waves.NewWaveManager(waveDefs)
 
-	walkable := flow.BuildWalkability(grid)
-	flowField := flow.Compute(grid.Width, grid.Height, walkable, m.Base.X, m.Base.Y)
-
 	g := &Game{
 		Map:         m,
 		Grid:        grid,
@@ -97,12 +125,13 @@ func NewGameFromMap(m *mapdata.GameMap) *Game {
 		Projectiles: []*entities.Projectile{},
 		Walls:       nil,
 
-		Wave:      waveMgr,
-		EnemyDB:   enemyDB,
-		FlowField: flowField,
-		Walkable:  walkable,
+		Wave:     waveMgr,
+		EnemyDB:  enemyDB,
+		TowerDB:  towerDB,
+		Walkable: flow.BuildWalkability(grid),
 
-		Money: 500,
+		Options: opts,
+		Preset:  loadPreset(opts.Difficulty),
 
 		CursorX: grid.Width / 2,
 		CursorY: grid.Height / 2,
@@ -113,19 +142,25 @@ func NewGameFromMap(m *mapdata.GameMap) *Game {
 		Y:  m.Base.Y,
 		HP: m.Base.HP,
 	}
+	g.Base.HP = g.scaledBaseHP(m.Base.HP)
+	g.refreshFlow()
+	g.Money = g.Preset.StartingMoney
 
 	g.Speed = 1.0
-	g.Difficulty = Difficulty{
-		SpeedMultiplier: 1.0,
-		SpawnMultiplier: 1.0,
-		CountBonus:      0,
-	}
+	g.Difficulty = newDifficulty(g.Preset)
 
 	totalWaves := len(waveDefs)
 	if totalWaves == 0 {
 		totalWaves = 5
 	}
 	g.Manager = NewGameManager(totalWaves, 5)
+	g.seedRNG()
+
+	if opts.Endless {
+		g.Generator = g.newGenerator()
+		g.Manager.Endless = true
+		g.ensureWave(0)
+	}
 
 	return g
 }
@@ -147,10 +182,11 @@ func newGameLegacy() *Game {
 		Projectiles: []*entities.Projectile{},
 		Walls:       nil,
 
+		TowerDB:   loadTowerDB(),
 		FlowField: flowField,
 		Walkable:  walkable,
 
-		Money: 500,
+		Preset: difficulty.Neutral(),
 
 		CursorX: grid.Width / 2,
 		CursorY: grid.Height / 2,
@@ -163,11 +199,7 @@ func newGameLegacy() *Game {
 	}
 
 	g.Speed = 1.0
-	g.Difficulty = Difficulty{
-		SpeedMultiplier: 1.0,
-		SpawnMultiplier: 1.0,
-		CountBonus:      0,
-	}
+	g.Difficulty = newDifficulty(g.Preset)
 
 	g.LegacyWave = WaveManager{
 		CurrentWave:    1,
@@ -178,11 +210,22 @@ func newGameLegacy() *Game {
 	}
 
 	g.Manager = NewGameManager(g.LegacyWave.TotalWaves, 5)
+	g.seedRNG()
 
 	return g
 }
 
-func (g *Game) spawnEnemy(enemyTypeID string, spawnID string) {
+// loadTowerDB returns the built-in tower catalog, or an empty one if it fails to load.
+func loadTowerDB() *towers.TowerDatabase {
+	towerDB, err := towers.DefaultTowers()
+	if err != nil {
+		log.Printf("load towers: %v, using empty catalog", err)
+		towerDB = &towers.TowerDatabase{Towers: make(map[string]towers.TowerDef)}
+	}
+	return towerDB
+}
+
+func (g *Game) spawnEnemy(enemyTypeID string, spawnID string, waveIndex int) *entities.Enemy {
 	var path mapdata.Path
 	if g.Map != nil {
 		path = g.Map.Paths[spawnID]
@@ -193,11 +236,21 @@ func (g *Game) spawnEnemy(enemyTypeID string, spawnID string) {
 		path = g.Path
 	}
 
+	enemy := g.newEnemy(enemyTypeID, path)
+	enemy.WaveIndex = waveIndex
+	enemy.SpawnID = spawnID
+	g.addEnemy(enemy)
+	log.Printf("DEBUG: Enemy spawned (type=%s spawn=%s wave=%d pos=(%.1f,%.1f) Alive: %d)", enemyTypeID, spawnID, waveIndex+1, enemy.X, enemy.Y, g.GetEnemiesAlive())
+	return enemy
+}
+
+// newEnemy builds an enemy of the given type at the start of path, with difficulty applied.
+func (g *Game) newEnemy(enemyTypeID string, path mapdata.Path) *entities.Enemy {
 	var enemy *entities.Enemy
 	if g.EnemyDB != nil {
 		def := g.EnemyDB.Get(enemyTypeID)
 		if def != nil {
-			enemy = entities.NewEnemyFromDef(def.HP, def.Speed, def.Reward, def.ID, path)
+			enemy = entities.NewEnemyFromDef(def, path)
 		} else {
 			log.Printf("WARN: enemy type %q not found, using basic", enemyTypeID)
 			enemy = entities.NewEnemy(path)
@@ -207,13 +260,18 @@ func (g *Game) spawnEnemy(enemyTypeID string, spawnID string) {
 	}
 
 	enemy.Speed *= g.Difficulty.SpeedMultiplier
+	enemy.HP *= g.Difficulty.HPMultiplier
+	enemy.MaxHP *= g.Difficulty.HPMultiplier
+	return enemy
+}
 
+// addEnemy puts an enemy into play and counts it toward its wave.
+func (g *Game) addEnemy(enemy *entities.Enemy) {
 	g.Enemies = append(g.Enemies, enemy)
 
 	if g.Wave != nil {
-		g.Wave.EnemiesAlive++
+		g.Wave.EnemyAdded(enemy.WaveIndex)
 	}
-	log.Printf("DEBUG: Enemy spawned (type=%s spawn=%s pos=(%.1f,%.1f) Alive: %d)", enemyTypeID, spawnID, enemy.X, enemy.Y, g.Wave.EnemiesAlive)
 }
 
 func (g *Game) updateSpawning(dt float64) {
@@ -226,37 +284,77 @@ func (g *Game) updateSpawning(dt float64) {
 		return
 	}
 
-	if len(g.Wave.ActiveGroups) == 0 && g.Wave.CurrentWave < len(g.Wave.Waves) {
-		log.Printf("DEBUG: Wave %d spawning started", g.Wave.CurrentWave+1)
-		g.Wave.StartWave()
+	if len(g.Wave.Active) == 0 && g.Wave.HasNext() {
+		g.startNextWave()
 	}
 
-	for i := range g.Wave.ActiveGroups {
-		group := &g.Wave.ActiveGroups[i]
-		if group.Completed {
-			continue
+	for w := range g.Wave.Active {
+		aw := &g.Wave.Active[w]
+		for i := range aw.Groups {
+			g.updateSpawnGroup(&aw.Groups[i], aw.Index, dt)
 		}
+	}
+}
 
-		if group.DelayTimer > 0 {
-			group.DelayTimer -= dt
-			continue
-		}
+// startNextWave starts spawning the next wave with difficulty applied.
+func (g *Game) startNextWave() {
+	aw := g.Wave.StartNext()
+	if aw == nil {
+		return
+	}
+	log.Printf("DEBUG: Wave %d spawning started", aw.Index+1)
+	g.scaleGroups(aw.Groups)
+}
+
+// updateSpawnGroup advances one spawn group of wave waveIndex.
+func (g *Game) updateSpawnGroup(group *waves.ActiveSpawnGroup, waveIndex int, dt float64) {
+	if group.Completed {
+		return
+	}
 
-		group.Timer += dt
+	if group.DelayTimer > 0 {
+		group.DelayTimer -= dt
+		return
+	}
 
-		if group.Timer >= group.Def.Interval && group.Spawned < group.Def.Count {
-			group.Timer = 0
-			g.spawnEnemy(group.Def.EnemyType, group.Def.SpawnID)
-			group.Spawned++
+	group.Timer += dt
 
-			if group.Spawned >= group.Def.Count {
-				group.Completed = true
-				log.Printf("DEBUG: Group completed (spawn=%s type=%s count=%d)", group.Def.SpawnID, group.Def.EnemyType, group.Spawned)
-			}
+	if group.Timer >= group.Interval && group.Spawned < group.Count {
+		group.Timer = 0
+		enemy := g.spawnEnemy(group.Def.EnemyType, group.Def.SpawnID, waveIndex)
+		if group.Def.Boss {
+			enemy.Boss = true
+			log.Printf("DEBUG: Boss %s entered the field", enemy.EnemyTypeID)
+		}
+		group.Spawned++
+
+		if group.Spawned >= group.Count {
+			group.Completed = true
+			log.Printf("DEBUG: Group completed (spawn=%s type=%s count=%d)", group.Def.SpawnID, group.Def.EnemyType, group.Spawned)
 		}
 	}
 }
 
+// scaleGroups applies the difficulty to the counts and intervals of a wave just started. Boss
+// groups keep their count; the wave's count bonus is spread over the other groups.
+func (g *Game) scaleGroups(groups []waves.ActiveSpawnGroup) {
+	n := 0
+	for _, group := range groups {
+		if !group.Def.Boss {
+			n++
+		}
+	}
+	k := 0
+	for i := range groups {
+		group := &groups[i]
+		if !group.Def.Boss {
+			group.Count = g.Difficulty.GroupCount(group.Def.Count) + g.Difficulty.GroupBonus(k, n)
+			k++
+		}
+		group.Interval = g.Difficulty.GroupInterval(group.Def.Interval)
+	}
+}
+
 func (g *Game) updateSpawningLegacy(dt float64) {
 	w := &g.LegacyWave
 
@@ -277,7 +375,7 @@ func (g *Game) updateSpawningLegacy(dt float64) {
 
 	if w.SpawnTimer >= w.SpawnInterval && w.EnemiesSpawned < w.EnemiesPerWave {
 		w.SpawnTimer = 0
-		g.spawnEnemy("basic", "default")
+		g.spawnEnemy("basic", "default", -1)
 	}
 
 	if w.EnemiesSpawned == w.EnemiesPerWave {
@@ -289,13 +387,33 @@ func (g *Game) updateSpawningLegacy(dt float64) {
 
 const flowReachedBaseDist = 0.5
 
+// terrainSpeed is the speed factor of the tile under e. Flying enemies ignore terrain.
+func (g *Game) terrainSpeed(e *entities.Enemy) float64 {
+	x, y := int(e.X), int(e.Y)
+	if e.Flying || x < 0 || x >= g.Grid.Width || y < 0 || y >= g.Grid.Height {
+		return 1
+	}
+	return g.Grid.Tiles[y][x].SpeedFactor()
+}
+
+// RangeBonusAt is the range a tower built at (x, y) gets from the terrain.
+func (g *Game) RangeBonusAt(x, y int) float64 {
+	if x < 0 || x >= g.Grid.Width || y < 0 || y >= g.Grid.Height {
+		return 0
+	}
+	return g.Grid.Tiles[y][x].RangeBonus()
+}
+
 func (g *Game) updateEnemies(dt float64) {
 	alive := []*entities.Enemy{}
+	var children []*entities.Enemy
 
 	for _, e := range g.Enemies {
 		if e.HP <= 0 {
+			children = append(children, g.splitChildren(e)...)
+			e.Effects.Clear()
 			if g.Wave != nil {
-				g.Wave.EnemiesAlive--
+				g.Wave.EnemyRemoved(e.WaveIndex)
 			} else {
 				g.LegacyWave.EnemiesAlive--
 			}
@@ -303,8 +421,17 @@ func (g *Game) updateEnemies(dt float64) {
 			continue
 		}
 
-		if g.FlowField != nil {
-			dist, dir := g.FlowField.AtFloat(e.X, e.Y)
+		e.Terrain = g.terrainSpeed(e)
+		if e.Flying {
+			e.UpdateFlying(dt, g.Base.X, g.Base.Y)
+		} else if g.FlowField != nil {
+			field := g.enemyField(e)
+			if e.FieldSize > 1 && field == g.FlowField {
+				// No wide route from here: squeeze through narrow corridors from now on.
+				log.Printf("DEBUG: size %d enemy at (%.1f,%.1f) has no wide route, squeezing through", e.Size, e.X, e.Y)
+				e.FieldSize = 1
+			}
+			dist, dir := field.AtFloat(e.X, e.Y)
 			if dist >= flow.Inf {
 				log.Printf("DEBUG: flow unreachable at (%.1f,%.1f) dist=Inf → marking reached base", e.X, e.Y)
 				e.ReachedBase = true
@@ -318,9 +445,11 @@ func (g *Game) updateEnemies(dt float64) {
 		}
 
 		if e.ReachedBase {
-			g.Base.HP--
+			e.Effects.Clear()
+			g.Base.HP -= e.LeakDamage()
+			g.Stats.recordLeak(e)
 			if g.Wave != nil {
-				g.Wave.EnemiesAlive--
+				g.Wave.EnemyRemoved(e.WaveIndex)
 			} else {
 				g.LegacyWave.EnemiesAlive--
 			}
@@ -336,6 +465,9 @@ func (g *Game) updateEnemies(dt float64) {
 	}
 
 	g.Enemies = alive
+	for _, c := range children {
+		g.addEnemy(c)
+	}
 }
 
 // FlowDebugString returns a short debug line when flow field is active and there are enemies (for on-screen debug).
@@ -360,7 +492,10 @@ func (g *Game) GetEnemiesAlive() int {
 
 func (g *Game) GetCurrentWave() int {
 	if g.Wave != nil {
-		return g.Wave.CurrentWave + 1
+		if len(g.Wave.Active) > 0 {
+			return g.Wave.Next // the latest started wave
+		}
+		return min(g.Wave.Next+1, max(len(g.Wave.Waves), 1))
 	}
 	return g.LegacyWave.CurrentWave
 }
@@ -378,7 +513,7 @@ func (g *Game) GetNextWaveSpawnIDs() map[string]bool {
 	if g.Wave == nil || g.Map == nil {
 		return spawnIDs
 	}
-	nextWaveIndex := g.Wave.CurrentWave
+	nextWaveIndex := g.Wave.Next
 	if nextWaveIndex >= 0 && nextWaveIndex < len(g.Wave.Waves) {
 		wave := g.Wave.Waves[nextWaveIndex]
 		for _, group := range wave.Groups {
@@ -417,21 +552,29 @@ func (g *Game) updateWaveState() {
 		return
 	}
 
-	if g.Wave.IsWaveComplete() {
-		waveNum := g.Wave.CurrentWave + 1
-		log.Printf("DEBUG: Wave %d cleared! Score: %d (+100)", waveNum, g.Score.Points+100)
+	cleared := g.Wave.PopCleared()
+	for _, aw := range cleared {
+		waveNum := aw.Index + 1
+		bonus := g.Wave.ClearBonus(aw.Index)
+		log.Printf("DEBUG: Wave %d cleared! Score: %d (+%d)", waveNum, g.Score.Points+bonus, bonus)
 
 		g.Score.WavesCleared++
-		g.Score.Points += 100
+		g.Score.Points += bonus
+		if g.Wave.IsBossWave(aw.Index) {
+			g.Money += bonus
+		}
 
 		g.Difficulty.SpeedMultiplier += 0.1
 		g.Difficulty.SpawnMultiplier += 0.05
 		g.Difficulty.CountBonus += 1
+	}
 
+	// The run pauses between waves only once every active wave is cleared.
+	if len(cleared) > 0 && len(g.Wave.Active) == 0 {
+		g.ensureWave(g.Wave.Next)
 		g.Manager.EndWave()
-
-		if g.Wave.NextWave() {
-			log.Printf("DEBUG: Starting wave %d", g.Wave.CurrentWave+1)
+		if g.Wave.HasNext() {
+			log.Printf("DEBUG: Next wave is %d", g.Wave.Next+1)
 		} else {
 			log.Println("DEBUG: All waves completed!")
 		}
@@ -462,14 +605,47 @@ func (g *Game) updateWaveStateLegacy() {
 	}
 }
 
+// Update advances the run by dt seconds of real time scaled by Speed. The time is consumed
+// in fixed SimStep steps, so the outcome does not depend on frame rate or speed setting.
 func (g *Game) Update(dt float64) {
-	scaled := dt * g.Speed
+	g.applyReplay()
+	if !g.stepping() {
+		g.accumulator = 0
+		return
+	}
+	g.accumulator += dt * g.Speed
+	for g.accumulator >= SimStep && g.stepping() {
+		g.accumulator -= SimStep
+		g.Step()
+		g.applyReplay()
+	}
+}
+
+// Step advances the run by exactly one SimStep.
+func (g *Game) Step() {
+	g.Manager.Update(SimStep)
+	if g.Manager.IsSimulationRunning() {
+		g.updateSpawning(SimStep)
+		g.updateTowers(SimStep)
+		g.updateProjectiles(SimStep)
+		g.updateEffects(SimStep)
+		g.updateAbilities(SimStep)
+		g.updatePathing()
+		g.updateEnemies(SimStep)
+		g.updateWaveState()
+	}
+	g.Tick++
+}
+
+// stepping reports whether the clock is running (waiting for a wave or in an unpaused wave).
+func (g *Game) stepping() bool {
+	return g.Manager.State == StatePreWave || g.Manager.IsSimulationRunning()
+}
 
-	g.updateSpawning(scaled)
-	g.updateTowers(scaled)
-	g.updateProjectiles(scaled)
-	g.updateEnemies(scaled)
-	g.updateWaveState()
+// Begin leaves the menu and starts the countdown to the first wave.
+func (g *Game) Begin() {
+	g.Manager.State = StatePreWave
+	g.Manager.InterWaveTimer = 5.0
 }
 
 func (g *Game) Reset() {
@@ -481,19 +657,17 @@ func (g *Game) Reset() {
 	g.RecomputeFlow()
 
 	if g.Map != nil {
-		g.Base.HP = g.Map.Base.HP
+		g.Base.HP = g.scaledBaseHP(g.Map.Base.HP)
 	} else {
-		g.Base.HP = 10
+		g.Base.HP = g.scaledBaseHP(10)
 	}
-	g.Money = 500
+	g.Money = g.Preset.StartingMoney
 
 	g.CursorX = g.Grid.Width / 2
 	g.CursorY = g.Grid.Height / 2
 
 	if g.Wave != nil {
-		g.Wave.CurrentWave = 0
-		g.Wave.ActiveGroups = nil
-		g.Wave.EnemiesAlive = 0
+		g.Wave.Reset()
 	} else {
 		g.LegacyWave = WaveManager{
 			CurrentWave:    1,
@@ -503,23 +677,23 @@ func (g *Game) Reset() {
 		}
 	}
 
-	g.Difficulty = Difficulty{
-		SpeedMultiplier: 1.0,
-		SpawnMultiplier: 1.0,
-		CountBonus:      0,
-	}
+	g.Difficulty = newDifficulty(g.Preset)
 
 	g.Score = Score{}
+	g.Stats = Stats{}
 
 	g.Manager.Reset()
-}
+	g.seedRNG()
+	g.rewindReplay()
 
-func (g *Game) CanPlaceTower(x, y int) bool {
-	if x < 0 || x >= g.Grid.Width || y < 0 || y >= g.Grid.Height {
-		return false
+	if g.Options.Endless && g.Wave != nil {
+		g.Generator = g.newGenerator()
+		g.ensureWave(0)
 	}
+}
 
-	if g.Grid.Tiles[y][x] == mapdata.PathTile || g.Grid.Tiles[y][x] == mapdata.SpawnTile || g.Grid.Tiles[y][x] == mapdata.BaseTile {
+func (g *Game) CanPlaceTower(x, y int) bool {
+	if !g.Grid.Buildable(x, y) {
 		return false
 	}
 
@@ -532,29 +706,51 @@ func (g *Game) CanPlaceTower(x, y int) bool {
 	return true
 }
 
-func (g *Game) PlaceTower(towerType entities.TowerType) bool {
+// BuildTowerDef returns the tower type currently selected in the build HUD, or nil if the catalog is empty.
+func (g *Game) BuildTowerDef() *towers.TowerDef {
+	if g.TowerDB == nil {
+		return nil
+	}
+	return g.TowerDB.At(g.Manager.BuildIndex)
+}
+
+// CycleBuildTower moves the build HUD selection by delta tower types (wraps around).
+func (g *Game) CycleBuildTower(delta int) {
+	if g.TowerDB == nil || len(g.TowerDB.Order) == 0 {
+		return
+	}
+	n := len(g.TowerDB.Order)
+	g.Manager.BuildIndex = ((g.Manager.BuildIndex+delta)%n + n) % n
+	log.Printf("DEBUG: Build tower type set to %q", g.TowerDB.Order[g.Manager.BuildIndex])
+}
+
+func (g *Game) PlaceTower(towerID string) bool {
 	if !g.CanPlaceTower(g.CursorX, g.CursorY) {
 		log.Printf("DEBUG: Cannot place tower at (%d, %d) - invalid location", g.CursorX, g.CursorY)
 		return false
 	}
 
-	templates := GetTowerTemplates()
-	template, exists := templates[towerType]
-
-	if !exists {
-		log.Printf("DEBUG: Tower type %d does not exist", towerType)
+	if g.TowerDB == nil {
+		log.Printf("DEBUG: No tower catalog loaded")
+		return false
+	}
+	def := g.TowerDB.Get(towerID)
+	if def == nil {
+		log.Printf("DEBUG: Tower type %q does not exist", towerID)
 		return false
 	}
 
-	if g.Money < template.Cost {
-		log.Printf("DEBUG: Insufficient funds to place tower (Have: %d, Need: %d)", g.Money, template.Cost)
+	if g.Money < def.Cost {
+		log.Printf("DEBUG: Insufficient funds to place tower (Have: %d, Need: %d)", g.Money, def.Cost)
 		return false
 	}
 
-	tower := entities.NewTower(g.CursorX, g.CursorY, towerType)
+	tower := entities.NewTower(g.CursorX, g.CursorY, def)
+	tower.Range += g.RangeBonusAt(g.CursorX, g.CursorY)
 	g.Towers = append(g.Towers, tower)
-	g.Money -= template.Cost
-	log.Printf("DEBUG: Tower placed at (%d, %d), Money remaining: %d, Total towers: %d", g.CursorX, g.CursorY, g.Money, len(g.Towers))
+	g.towersChanged()
+	g.Money -= def.Cost
+	log.Printf("DEBUG: Tower %q placed at (%d, %d), Money remaining: %d, Total towers: %d", def.ID, g.CursorX, g.CursorY, g.Money, len(g.Towers))
 
 	return true
 }
@@ -568,7 +764,12 @@ func (g *Game) GetTowerAt(x, y int) *entities.Tower {
 	return nil
 }
 
-// ComputeBlockedTiles returns path tiles that lie on any wall segment (spawn/base stay walkable).
+// wallBlocks reports whether a wall across tile t blocks it: walkable tiles other than spawns and the base.
+func wallBlocks(t mapdata.TileType) bool {
+	return t.Walkable() && t != mapdata.SpawnTile && t != mapdata.BaseTile
+}
+
+// ComputeBlockedTiles returns walkable tiles that lie on any wall segment (spawn/base stay walkable).
 func (g *Game) ComputeBlockedTiles() [][2]int {
 	seen := make(map[[2]int]bool)
 	var out [][2]int
@@ -581,7 +782,7 @@ func (g *Game) ComputeBlockedTiles() [][2]int {
 			if y < 0 || y >= g.Grid.Height || x < 0 || x >= g.Grid.Width {
 				continue
 			}
-			if g.Grid.Tiles[y][x] != mapdata.PathTile {
+			if !wallBlocks(g.Grid.Tiles[y][x]) {
 				continue
 			}
 			seen[[2]int{x, y}] = true
@@ -591,14 +792,44 @@ func (g *Game) ComputeBlockedTiles() [][2]int {
 	return out
 }
 
-// RecomputeFlow rebuilds walkability (including wall blocks) and flow field.
+// RecomputeFlow rebuilds walkability (including wall blocks) and the flow fields.
 func (g *Game) RecomputeFlow() {
 	blocked := g.ComputeBlockedTiles()
 	g.Walkable = flow.BuildWalkabilityWithBlocked(g.Grid, blocked)
-	g.FlowField = flow.Compute(g.Grid.Width, g.Grid.Height, g.Walkable, g.Base.X, g.Base.Y)
+	g.refreshFlow()
 	log.Printf("DEBUG: Flow recomputed (blocked tiles: %d)", len(blocked))
 }
 
+// sizeField returns the flow field for enemies with a size×size footprint (only wide-enough corridors are walkable).
+func (g *Game) sizeField(size int) *flow.Field {
+	if size <= 1 || g.FlowField == nil {
+		return g.FlowField
+	}
+	if f, ok := g.SizeFields[size]; ok {
+		return f
+	}
+	walkable := flow.ErodeForSize(g.Walkable, size, g.Base.X, g.Base.Y)
+	f := g.computeField(walkable)
+	if g.SizeFields == nil {
+		g.SizeFields = make(map[int]*flow.Field)
+	}
+	g.SizeFields[size] = f
+	return f
+}
+
+// enemyField returns the field the enemy navigates with: its size's field, or the normal field
+// when there is no wide-enough route from where it stands. It does not change the enemy, so the
+// renderer may call it.
+func (g *Game) enemyField(e *entities.Enemy) *flow.Field {
+	if e.FieldSize > 1 {
+		f := g.sizeField(e.FieldSize)
+		if dist, _ := f.AtFloat(e.X, e.Y); dist < flow.Inf {
+			return f
+		}
+	}
+	return g.FlowField
+}
+
 const maxWallLinkDist = 4
 
 // GetLinkableTowers returns positions (x,y) of towers that can form a wall with the tower at (ax,ay). Order is stable for HUD numbering.
@@ -662,18 +893,58 @@ func (g *Game) RemoveWall(ax, ay, bx, by int) bool {
 
 const sellRefundPercent = 50
 
-// SellTower removes the tower at (x,y), refunds part of cost, and removes any walls using it. Returns true if sold.
-func (g *Game) SellTower(x, y int) bool {
+// SellRefund returns the money the tower at (x,y) would return when sold (part of total invested).
+func (g *Game) SellRefund(x, y int) int {
 	tower := g.GetTowerAt(x, y)
 	if tower == nil {
+		return 0
+	}
+	return (tower.Invested * sellRefundPercent) / 100
+}
+
+// TowerUpgrades returns the upgrades the tower at (x,y) can buy next (two entries when choosing a branch).
+func (g *Game) TowerUpgrades(x, y int) []towers.UpgradeOption {
+	tower := g.GetTowerAt(x, y)
+	if tower == nil || g.TowerDB == nil {
+		return nil
+	}
+	def := g.TowerDB.Get(tower.TypeID)
+	if def == nil {
+		return nil
+	}
+	return def.NextUpgrades(tower.Level, tower.Branch)
+}
+
+// UpgradeTower buys upgrade option choice (index into TowerUpgrades) for the tower at (x,y). Returns true if bought.
+func (g *Game) UpgradeTower(x, y, choice int) bool {
+	tower := g.GetTowerAt(x, y)
+	if tower == nil {
+		return false
+	}
+	options := g.TowerUpgrades(x, y)
+	if choice < 0 || choice >= len(options) {
+		log.Printf("DEBUG: Upgrade %d not available for tower at (%d,%d)", choice, x, y)
+		return false
+	}
+	opt := options[choice]
+	if g.Money < opt.Cost {
+		log.Printf("DEBUG: Insufficient funds to upgrade tower (Have: %d, Need: %d)", g.Money, opt.Cost)
 		return false
 	}
-	templates := GetTowerTemplates()
-	template, ok := templates[tower.Type]
-	if !ok {
-		template = TowerTemplate{Cost: 50}
+	g.Money -= opt.Cost
+	tower.ApplyUpgrade(opt)
+	g.towersChanged()
+	log.Printf("DEBUG: Tower at (%d,%d) upgraded to level %d (%s), Money remaining: %d", x, y, tower.Level, opt.Def.Name, g.Money)
+	return true
+}
+
+// SellTower removes the tower at (x,y), refunds part of total invested, and removes any walls using it. Returns true if sold.
+func (g *Game) SellTower(x, y int) bool {
+	tower := g.GetTowerAt(x, y)
+	if tower == nil {
+		return false
 	}
-	refund := (template.Cost * sellRefundPercent) / 100
+	refund := g.SellRefund(x, y)
 	g.Money += refund
 
 	var newWalls []Wall
@@ -748,7 +1019,7 @@ func (g *Game) WouldDisconnectSpawnsFromBase(ax, ay, bx, by int) bool {
 		if x < 0 || x >= g.Grid.Width || y < 0 || y >= g.Grid.Height {
 			continue
 		}
-		if g.Grid.Tiles[y][x] != mapdata.PathTile {
+		if !wallBlocks(g.Grid.Tiles[y][x]) {
 			continue
 		}
 		if !seen[[2]int{x, y}] {
@@ -768,7 +1039,7 @@ func (g *Game) WouldDisconnectSpawnsFromBase(ax, ay, bx, by int) bool {
 }
 
 func (g *Game) isEnemyInRange(tower *entities.Tower, enemy *entities.Enemy) bool {
-	dist := tower.DistanceTo(enemy.X, enemy.Y)
+	dist := tower.DistanceTo(enemy.Center())
 	return dist <= tower.Range && enemy.HP > 0
 }
 
@@ -778,11 +1049,12 @@ func (g *Game) updateTowers(dt float64) {
 			tower.Cooldown = maxFloat(0, tower.Cooldown-dt)
 		}
 
-		if tower.Target == nil || !g.isEnemyInRange(tower, tower.Target) {
+		if tower.Target == nil || !g.isEnemyInRange(tower, tower.Target) ||
+			(tower.Retarget == towers.RetargetEachShot && tower.Cooldown <= 0) {
 			oldTarget := tower.Target
-			tower.Target = g.findClosestEnemyInRange(tower)
+			tower.Target = g.selectTarget(tower)
 			if tower.Target != oldTarget && tower.Target != nil {
-				log.Printf("DEBUG: Tower at (%d, %d) acquired new target", tower.X, tower.Y)
+				log.Printf("DEBUG: Tower at (%d, %d) acquired new target (%s)", tower.X, tower.Y, tower.Targeting)
 			}
 		}
 
@@ -793,69 +1065,6 @@ func (g *Game) updateTowers(dt float64) {
 	}
 }
 
-func (g *Game) findClosestEnemyInRange(tower *entities.Tower) *entities.Enemy {
-	var closest *entities.Enemy
-	closestDist := tower.Range + 1.0
-
-	for _, enemy := range g.Enemies {
-		if enemy.HP <= 0 {
-			continue
-		}
-
-		dist := tower.DistanceTo(enemy.X, enemy.Y)
-		if dist <= tower.Range && dist < closestDist {
-			closest = enemy
-			closestDist = dist
-		}
-	}
-
-	return closest
-}
-
-func (g *Game) fireTower(tower *entities.Tower) {
-	if tower.Target == nil {
-		return
-	}
-
-	projectile := entities.NewProjectile(
-		float64(tower.X),
-		float64(tower.Y),
-		tower.Target,
-		20.0,
-		tower.Damage,
-	)
-
-	g.Projectiles = append(g.Projectiles, projectile)
-	log.Printf("DEBUG: Tower at (%d, %d) fired at enemy (HP: %.1f), Projectiles: %d", tower.X, tower.Y, tower.Target.HP, len(g.Projectiles))
-}
-
-func (g *Game) updateProjectiles(dt float64) {
-	active := []*entities.Projectile{}
-
-	for _, proj := range g.Projectiles {
-		proj.Update(dt)
-
-		if proj.HasHit {
-			if proj.TargetEnemy != nil && proj.TargetEnemy.HP > 0 {
-				proj.TargetEnemy.HP -= proj.Damage
-				if proj.TargetEnemy.HP <= 0 {
-					reward := proj.TargetEnemy.Reward
-					if reward == 0 {
-						reward = 10
-					}
-					g.Money += reward
-					g.Score.Points += reward
-				}
-			}
-			continue
-		}
-		if proj.TargetEnemy != nil && proj.TargetEnemy.HP > 0 {
-			active = append(active, proj)
-		}
-	}
-	g.Projectiles = active
-}
-
 func maxFloat(a, b float64) float64 {
 	if a > b {
 		return a
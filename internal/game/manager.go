@@ -32,8 +32,11 @@ type GameManager struct {
 	SelectingWallTarget       bool
 	SelectingWallRemoveTarget bool
 
+	BuildIndex int // index into the tower catalog build order
+
 	CurrentWave int
 	TotalWaves  int
+	Endless     bool // never won: waves keep coming until the base falls
 
 	InterWaveTimer float64
 	InterWaveDelay float64
@@ -78,7 +81,7 @@ func (m *GameManager) StartWave() {
 }
 
 func (m *GameManager) EndWave() {
-	if m.CurrentWave >= m.TotalWaves {
+	if m.CurrentWave >= m.TotalWaves && !m.Endless {
 		m.State = StateWon
 		log.Printf("DEBUG: All waves completed - game won! Run time: %.2fs", m.RunTime)
 		return
@@ -0,0 +1,71 @@
+package game
+
+import (
+	"log"
+	"sort"
+
+	"terminal-td/internal/waves"
+)
+
+// Options are per-run settings chosen before the map starts.
+type Options struct {
+	Endless bool  // keep generating waves after the map's scripted waves run out
+	Seed    int64 // seeds Game.RNG, the source of all simulation randomness
+
+	Difficulty string // difficulty preset ID ("" means the default preset)
+}
+
+// newGenerator builds the endless wave generator from the map's spawns and the enemy catalog.
+// The first generated wave is budgeted like the last scripted one.
+func (g *Game) newGenerator() *waves.Generator {
+	var spawnIDs []string
+	if g.Map != nil {
+		for _, s := range g.Map.Spawns {
+			spawnIDs = append(spawnIDs, s.ID)
+		}
+	}
+
+	cost := func(id string) float64 { return 0 }
+	var candidates []waves.Candidate
+	if g.EnemyDB != nil {
+		ids := make([]string, 0, len(g.EnemyDB.Enemies))
+		for id := range g.EnemyDB.Enemies {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			def := g.EnemyDB.Enemies[id]
+			candidates = append(candidates, waves.Candidate{
+				ID:     id,
+				HP:     def.HP,
+				Speed:  def.Speed,
+				Reward: def.Reward,
+				Boss:   len(def.Phases) > 0,
+			})
+		}
+		cost = func(id string) float64 {
+			def := g.EnemyDB.Get(id)
+			if def == nil {
+				return 0
+			}
+			return waves.EnemyCost(def.HP, def.Speed, def.Reward)
+		}
+	}
+
+	base := 0.0
+	if g.Wave != nil && g.Wave.Scripted > 0 {
+		base = waves.WaveBudget(g.Wave.Waves[g.Wave.Scripted-1], cost)
+	}
+	log.Printf("DEBUG: Endless generator ready (seed=%d spawns=%d candidates=%d base budget=%.0f)", g.Options.Seed, len(spawnIDs), len(candidates), base)
+	return waves.NewGenerator(g.RNG, spawnIDs, candidates, base)
+}
+
+// ensureWave generates waves in endless mode until wave index i exists.
+func (g *Game) ensureWave(i int) {
+	if !g.Options.Endless || g.Wave == nil || g.Generator == nil {
+		return
+	}
+	for len(g.Wave.Waves) <= i {
+		g.Wave.AppendWave(g.Generator.Next(len(g.Wave.Waves) + 1))
+	}
+}
@@ -0,0 +1,74 @@
+package game
+
+import (
+	"math"
+
+	"terminal-td/internal/flow"
+)
+
+// congestionRefreshSteps is how often, in sim steps, maps that weigh congestion rebuild their flow
+// fields around where enemies are crowding.
+const congestionRefreshSteps = 10
+
+// flowOptions returns the tile costs and movement rules of the flow fields: terrain, plus tower
+// threat and enemy congestion on maps whose pathing weighs them.
+func (g *Game) flowOptions() flow.Options {
+	opts := flow.Options{Costs: flow.TerrainCosts(g.Grid)}
+	if g.Map == nil {
+		return opts
+	}
+	p := g.Map.Pathing
+	opts.Diagonal = p.Diagonal
+	if p.Threat > 0 {
+		for _, t := range g.Towers {
+			r := int(math.Ceil(t.Range))
+			for y := max(t.Y-r, 0); y <= min(t.Y+r, g.Grid.Height-1); y++ {
+				for x := max(t.X-r, 0); x <= min(t.X+r, g.Grid.Width-1); x++ {
+					if math.Hypot(float64(x-t.X), float64(y-t.Y)) <= t.Range {
+						opts.Costs[y][x] += p.Threat
+					}
+				}
+			}
+		}
+	}
+	if p.Congestion > 0 {
+		for _, e := range g.Enemies {
+			x, y := int(e.X), int(e.Y)
+			if e.Flying || e.HP <= 0 || x < 0 || x >= g.Grid.Width || y < 0 || y >= g.Grid.Height {
+				continue
+			}
+			opts.Costs[y][x] += p.Congestion
+		}
+	}
+	return opts
+}
+
+// computeField builds a flow field toward the base over walkable with the tile costs of the last
+// refresh, so size fields built later on demand match FlowField whenever they are built.
+func (g *Game) computeField(walkable [][]bool) *flow.Field {
+	return flow.ComputeWithOptions(g.Grid.Width, g.Grid.Height, walkable, g.Base.X, g.Base.Y, g.flowOpts)
+}
+
+// refreshFlow rebuilds the flow fields over the current walkability, e.g. after tile costs changed.
+func (g *Game) refreshFlow() {
+	g.flowOpts = g.flowOptions()
+	g.FlowField = g.computeField(g.Walkable)
+	g.SizeFields = nil
+}
+
+// towersChanged refreshes the flow fields when towers were built, sold or upgraded on a map that
+// weighs tower threat.
+func (g *Game) towersChanged() {
+	if g.Map != nil && g.Map.Pathing.Threat > 0 {
+		g.refreshFlow()
+	}
+}
+
+// updatePathing refreshes the flow fields every congestionRefreshSteps steps on maps that weigh
+// congestion, so following enemies spread over other routes.
+func (g *Game) updatePathing() {
+	if g.Map == nil || g.Map.Pathing.Congestion <= 0 || g.Tick%congestionRefreshSteps != 0 {
+		return
+	}
+	g.refreshFlow()
+}
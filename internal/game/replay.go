@@ -0,0 +1,373 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"terminal-td/internal/config"
+	"terminal-td/internal/content"
+	"terminal-td/internal/enemies"
+	mapdata "terminal-td/internal/map"
+	"terminal-td/internal/towers"
+	"terminal-td/internal/waves"
+)
+
+const (
+	ReplayVersion = 2
+	ReplaysDir    = "replays"
+	maxReplays    = 20
+)
+
+// CommandType names a player command.
+type CommandType string
+
+const (
+	CmdPlaceTower     CommandType = "place_tower"
+	CmdSellTower      CommandType = "sell_tower"
+	CmdUpgradeTower   CommandType = "upgrade_tower"
+	CmdAddWall        CommandType = "add_wall"
+	CmdRemoveWall     CommandType = "remove_wall"
+	CmdCycleTargeting CommandType = "cycle_targeting"
+	CmdToggleRetarget CommandType = "toggle_retarget"
+	CmdCallWave       CommandType = "call_wave"
+	CmdSetSpeed       CommandType = "set_speed"
+	CmdTogglePause    CommandType = "toggle_pause"
+)
+
+// Command is a player action, stamped with the simulation tick it was issued at.
+type Command struct {
+	Tick   uint64      `json:"tick"`
+	Type   CommandType `json:"type"`
+	X      int         `json:"x,omitempty"`
+	Y      int         `json:"y,omitempty"`
+	X2     int         `json:"x2,omitempty"` // other wall end
+	Y2     int         `json:"y2,omitempty"`
+	Tower  string      `json:"tower,omitempty"`  // tower type for place_tower
+	Choice int         `json:"choice,omitempty"` // upgrade branch
+	Speed  float64     `json:"speed,omitempty"`
+}
+
+// Replay is a recorded run: the settings it started from and every command the player issued.
+type Replay struct {
+	Version     int       `json:"replay_version"`
+	GameVersion string    `json:"game_version"`
+	RecordedAt  string    `json:"recorded_at"`
+	MapID       string    `json:"map_id"`
+	Seed        int64     `json:"seed"`
+	Endless     bool      `json:"endless"`
+	Difficulty  string    `json:"difficulty"`
+	Mods        []ModRef  `json:"mods,omitempty"` // enabled mods in load order
+	ContentHash uint64    `json:"content_hash"`   // Game.ContentHash of the run
+	Commands    []Command `json:"commands"`
+	FinalTick   uint64    `json:"final_tick"`
+	Checksum    uint64    `json:"checksum"` // Game.Checksum at FinalTick
+}
+
+// ModRef names an enabled mod a replay was recorded with.
+type ModRef struct {
+	ID      string `json:"id"`
+	Version string `json:"version"`
+}
+
+func (m ModRef) String() string {
+	return m.ID + " " + m.Version
+}
+
+// Replay verification results.
+const (
+	ReplayPending  = ""
+	ReplayVerified = "verified"
+	ReplayDesync   = "desync"
+)
+
+// playback drives a Game from a recorded replay.
+type playback struct {
+	replay *Replay
+	next   int    // index of the next command to apply
+	result string // ReplayPending until FinalTick is reached
+}
+
+// StartRecording begins recording player commands for a replay.
+func (g *Game) StartRecording() {
+	if g.Map == nil {
+		return
+	}
+	g.Recording = &Replay{
+		Version:     ReplayVersion,
+		GameVersion: Version,
+		MapID:       g.Map.ID,
+		Seed:        g.Options.Seed,
+		Endless:     g.Options.Endless,
+		Difficulty:  g.Options.Difficulty,
+		Mods:        enabledMods(),
+		ContentHash: g.ContentHash(),
+	}
+}
+
+// enabledMods returns the mods content is loaded from, in load order.
+func enabledMods() []ModRef {
+	var refs []ModRef
+	for _, src := range content.Mods() {
+		refs = append(refs, ModRef{ID: src.ModID, Version: src.ModVersion})
+	}
+	return refs
+}
+
+// ContentHash hashes the data a run is built from: the map, its scripted waves and the enemy and
+// tower definitions. Mods and custom content change it; a replay only plays back against the
+// data it was recorded with.
+func (g *Game) ContentHash() uint64 {
+	var scripted []waves.WaveDef
+	if g.Wave != nil {
+		scripted = g.Wave.Waves[:g.Wave.Scripted]
+	}
+	data, err := json.Marshal(struct {
+		Grid    *mapdata.Grid
+		Spawns  []mapdata.SpawnPoint
+		Paths   map[string]mapdata.Path
+		Base    mapdata.BaseInfo
+		Pathing mapdata.PathingDef
+		Waves   []waves.WaveDef
+		Enemies *enemies.EnemyDatabase
+		Towers  *towers.TowerDatabase
+	}{g.Map.Grid, g.Map.Spawns, g.Map.Paths, g.Map.Base, g.Map.Pathing, scripted, g.EnemyDB, g.TowerDB})
+	if err != nil {
+		log.Printf("WARN: content hash: %v", err)
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write(data)
+	return h.Sum64()
+}
+
+// Replaying reports whether the game is playing back a replay.
+func (g *Game) Replaying() bool {
+	return g.playback != nil
+}
+
+// ReplayProgress returns the current and final tick of the replay and its verification result.
+func (g *Game) ReplayProgress() (tick, final uint64, result string) {
+	if g.playback == nil {
+		return 0, 0, ReplayPending
+	}
+	return g.Tick, g.playback.replay.FinalTick, g.playback.result
+}
+
+// Apply runs a player command at the current tick and records it. Player commands are
+// ignored while a replay is playing.
+func (g *Game) Apply(c Command) bool {
+	if g.playback != nil {
+		return false
+	}
+	c.Tick = g.Tick
+	if !g.execute(c) {
+		return false
+	}
+	if g.Recording != nil {
+		g.Recording.Commands = append(g.Recording.Commands, c)
+	}
+	return true
+}
+
+func (g *Game) execute(c Command) bool {
+	switch c.Type {
+	case CmdPlaceTower:
+		g.CursorX, g.CursorY = c.X, c.Y
+		return g.PlaceTower(c.Tower)
+	case CmdSellTower:
+		return g.SellTower(c.X, c.Y)
+	case CmdUpgradeTower:
+		return g.UpgradeTower(c.X, c.Y, c.Choice)
+	case CmdAddWall:
+		return g.AddWall(c.X, c.Y, c.X2, c.Y2)
+	case CmdRemoveWall:
+		return g.RemoveWall(c.X, c.Y, c.X2, c.Y2)
+	case CmdCycleTargeting:
+		return g.CycleTargeting(c.X, c.Y)
+	case CmdToggleRetarget:
+		return g.ToggleRetarget(c.X, c.Y)
+	case CmdCallWave:
+		return g.CallNextWave()
+	case CmdSetSpeed:
+		if c.Speed <= 0 || c.Speed == g.Speed {
+			return false
+		}
+		g.Speed = c.Speed
+		return true
+	case CmdTogglePause:
+		if g.Manager.State != StateInWave && g.Manager.State != StatePaused {
+			return false
+		}
+		g.Manager.TogglePause()
+		return true
+	}
+	log.Printf("WARN: unknown command type %q", c.Type)
+	return false
+}
+
+// applyReplay applies the replay commands due at the current tick and verifies the checksum
+// once FinalTick is reached. Speed and pause commands are skipped: the viewer controls both,
+// and neither changes the outcome.
+func (g *Game) applyReplay() {
+	p := g.playback
+	if p == nil {
+		return
+	}
+	cmds := p.replay.Commands
+	for p.next < len(cmds) && cmds[p.next].Tick <= g.Tick {
+		c := cmds[p.next]
+		p.next++
+		if c.Type == CmdSetSpeed || c.Type == CmdTogglePause {
+			continue
+		}
+		if !g.execute(c) {
+			log.Printf("WARN: replay command %s at tick %d had no effect", c.Type, c.Tick)
+		}
+	}
+	if p.result == ReplayPending && g.Tick == p.replay.FinalTick {
+		if g.Checksum() == p.replay.Checksum {
+			p.result = ReplayVerified
+			log.Printf("DEBUG: Replay verified at tick %d", g.Tick)
+		} else {
+			p.result = ReplayDesync
+			log.Printf("WARN: Replay desync at tick %d (checksum %x, recorded %x)", g.Tick, g.Checksum(), p.replay.Checksum)
+		}
+	}
+}
+
+// rewindReplay restarts recording or playback after Reset.
+func (g *Game) rewindReplay() {
+	if g.Recording != nil {
+		g.Recording.Commands = nil
+	}
+	if g.playback != nil {
+		g.playback.next = 0
+		g.playback.result = ReplayPending
+	}
+}
+
+// NewReplayGame builds a game that plays back r. It refuses replays recorded with other mods
+// or other map, wave, enemy or tower data, which would not play back the same.
+func NewReplayGame(r *Replay) (*Game, error) {
+	if mods := enabledMods(); !slices.Equal(mods, r.Mods) {
+		return nil, fmt.Errorf("replay was recorded with %s but the game has %s enabled", modList(r.Mods), modList(mods))
+	}
+	m, err := mapdata.LoadMapByID(r.MapID)
+	if err != nil {
+		return nil, fmt.Errorf("load map %q: %w", r.MapID, err)
+	}
+	g := NewGameWithOptions(m, Options{
+		Endless:    r.Endless,
+		Seed:       r.Seed,
+		Difficulty: r.Difficulty,
+	})
+	if sum := g.ContentHash(); sum != r.ContentHash {
+		return nil, fmt.Errorf("replay was recorded with different map, wave, enemy or tower data (content hash %016x, now %016x)", r.ContentHash, sum)
+	}
+	g.playback = &playback{replay: r}
+	g.Begin()
+	log.Printf("DEBUG: Replay loaded (map=%s seed=%d commands=%d final tick=%d)", r.MapID, r.Seed, len(r.Commands), r.FinalTick)
+	return g, nil
+}
+
+// modList describes mods for messages.
+func modList(mods []ModRef) string {
+	if len(mods) == 0 {
+		return "no mods"
+	}
+	names := make([]string, len(mods))
+	for i, m := range mods {
+		names[i] = m.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// LoadReplay reads a replay file, rejecting replays this version cannot play back.
+func LoadReplay(path string) (*Replay, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Replay
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("replay decode: %w", err)
+	}
+	if r.Version != ReplayVersion {
+		return nil, fmt.Errorf("replay format version %d is not supported (this game reads version %d)", r.Version, ReplayVersion)
+	}
+	if r.GameVersion != Version {
+		return nil, fmt.Errorf("replay was recorded with game version %s and cannot be played back by version %s", r.GameVersion, Version)
+	}
+	return &r, nil
+}
+
+// WriteReplay finishes the recording at the current tick and writes it to the replays
+// directory, keeping the newest maxReplays files. It returns the file path.
+func (g *Game) WriteReplay() (string, error) {
+	r := g.Recording
+	if r == nil {
+		return "", fmt.Errorf("no replay is being recorded")
+	}
+	r.FinalTick = g.Tick
+	r.Checksum = g.Checksum()
+	r.RecordedAt = time.Now().UTC().Format(time.RFC3339)
+
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, ReplaysDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("replay-%s-%s.json", r.MapID, time.Now().Format("20060102-150405")))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	pruneReplays(dir)
+	log.Printf("DEBUG: Replay written to %s (%d commands, final tick %d)", path, len(r.Commands), r.FinalTick)
+	return path, nil
+}
+
+func pruneReplays(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), "replay-") && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	if len(names) <= maxReplays {
+		return
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return replayStamp(names[i]) < replayStamp(names[j])
+	})
+	for _, n := range names[:len(names)-maxReplays] {
+		_ = os.Remove(filepath.Join(dir, n))
+	}
+}
+
+// replayStamp returns the timestamp suffix of a replay file name, which sorts chronologically.
+func replayStamp(name string) string {
+	name = strings.TrimSuffix(name, ".json")
+	if len(name) < len("20060102-150405") {
+		return name
+	}
+	return name[len(name)-len("20060102-150405"):]
+}
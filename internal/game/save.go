@@ -0,0 +1,665 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"terminal-td/internal/config"
+	"terminal-td/internal/damage"
+	"terminal-td/internal/effects"
+	"terminal-td/internal/entities"
+	mapdata "terminal-td/internal/map"
+	"terminal-td/internal/towers"
+	"terminal-td/internal/waves"
+)
+
+const (
+	SaveVersion  = 1
+	SaveFileName = "save.json"
+)
+
+// Saved manager states. A run saved mid-wave resumes paused.
+const (
+	savedStatePreWave = "pre_wave"
+	savedStateInWave  = "in_wave"
+)
+
+// SaveFile is the on-disk snapshot of an in-progress run. Enemies and projectiles refer to
+// each other by index into Enemies (-1 for none).
+type SaveFile struct {
+	Version     int    `json:"save_version"`
+	GameVersion string `json:"game_version"`
+	SavedAt     string `json:"saved_at"`
+	MapID       string `json:"map_id"`
+
+	Options    savedOptions    `json:"options"`
+	Tick       uint64          `json:"tick"`
+	RNGState   []byte          `json:"rng_state,omitempty"` // empty in saves from before the shared RNG
+	Money      int             `json:"money"`
+	BaseHP     int             `json:"base_hp"`
+	Speed      float64         `json:"speed"`
+	Score      savedScore      `json:"score"`
+	Stats      Stats           `json:"stats"`
+	Difficulty savedDifficulty `json:"difficulty"`
+	Manager    savedManager    `json:"manager"`
+	Waves      savedWaves      `json:"waves"`
+
+	Towers      []savedTower      `json:"towers"`
+	Walls       []savedWall       `json:"walls"`
+	Enemies     []savedEnemy      `json:"enemies"`
+	Projectiles []savedProjectile `json:"projectiles"`
+}
+
+type savedOptions struct {
+	Endless    bool   `json:"endless"`
+	Seed       int64  `json:"seed"`
+	Difficulty string `json:"difficulty"`
+}
+
+type savedScore struct {
+	Points        int `json:"points"`
+	EnemiesKilled int `json:"enemies_killed"`
+	WavesCleared  int `json:"waves_cleared"`
+}
+
+type savedDifficulty struct {
+	SpeedMultiplier    float64 `json:"speed_multiplier"`
+	SpawnMultiplier    float64 `json:"spawn_multiplier"`
+	CountBonus         int     `json:"count_bonus"`
+	HPMultiplier       float64 `json:"hp_multiplier"`
+	CountMultiplier    float64 `json:"count_multiplier"`
+	IntervalMultiplier float64 `json:"interval_multiplier"`
+	RewardMultiplier   float64 `json:"reward_multiplier"`
+}
+
+type savedManager struct {
+	State          string  `json:"state"`
+	CurrentWave    int     `json:"current_wave"`
+	InterWaveTimer float64 `json:"inter_wave_timer"`
+	RunTime        float64 `json:"run_time"`
+	BuildIndex     int     `json:"build_index"`
+}
+
+type savedWaves struct {
+	Next      int               `json:"next"`
+	Generated []waves.WaveDef   `json:"generated"` // endless waves generated so far
+	Active    []savedActiveWave `json:"active"`
+}
+
+type savedActiveWave struct {
+	Index  int          `json:"index"`
+	Groups []savedGroup `json:"groups"`
+}
+
+type savedGroup struct {
+	Def        waves.SpawnGroupDef `json:"def"`
+	Count      int                 `json:"count"`
+	Interval   float64             `json:"interval"`
+	Spawned    int                 `json:"spawned"`
+	Timer      float64             `json:"timer"`
+	DelayTimer float64             `json:"delay_timer"`
+	Completed  bool                `json:"completed"`
+}
+
+type savedTower struct {
+	TypeID    string  `json:"type_id"`
+	X         int     `json:"x"`
+	Y         int     `json:"y"`
+	Range     float64 `json:"range"`
+	Damage    float64 `json:"damage"`
+	FireRate  float64 `json:"fire_rate"`
+	Level     int     `json:"level"`
+	Branch    string  `json:"branch"`
+	Invested  int     `json:"invested"`
+	Cooldown  float64 `json:"cooldown"`
+	Targeting string  `json:"targeting"`
+	Retarget  string  `json:"retarget"`
+	Target    int     `json:"target"`
+	Dealt     float64 `json:"damage_dealt"`
+}
+
+type savedWall struct {
+	Ax int `json:"ax"`
+	Ay int `json:"ay"`
+	Bx int `json:"bx"`
+	By int `json:"by"`
+}
+
+type savedEnemy struct {
+	TypeID    string             `json:"type_id"`
+	X         float64            `json:"x"`
+	Y         float64            `json:"y"`
+	Speed     float64            `json:"speed"`
+	HP        float64            `json:"hp"`
+	MaxHP     float64            `json:"max_hp"`
+	Reward    int                `json:"reward"`
+	Path      []mapdata.PointDef `json:"path"`
+	PathIndex int                `json:"path_index"`
+	FieldSize int                `json:"field_size"`
+	WaveIndex int                `json:"wave_index"`
+	SpawnID   string             `json:"spawn_id"`
+	Boss      bool               `json:"boss"`
+	Phase     int                `json:"phase"`
+	Shield    float64            `json:"shield"`
+	Flying    bool               `json:"flying"`
+	Effects   []savedStatus      `json:"effects"`
+	Abilities []float64          `json:"ability_timers"`
+}
+
+type savedStatus struct {
+	Kind      string  `json:"kind"`
+	Magnitude float64 `json:"magnitude"`
+	Remaining float64 `json:"remaining"`
+	Stacks    int     `json:"stacks"`
+	MaxStacks int     `json:"max_stacks"`
+}
+
+type savedProjectile struct {
+	X           float64              `json:"x"`
+	Y           float64              `json:"y"`
+	PrevX       float64              `json:"prev_x"`
+	PrevY       float64              `json:"prev_y"`
+	TargetX     float64              `json:"target_x"`
+	TargetY     float64              `json:"target_y"`
+	Target      int                  `json:"target"`
+	Source      int                  `json:"source"` // index into Towers, -1 for none
+	Speed       float64              `json:"speed"`
+	Damage      float64              `json:"damage"`
+	DamageType  string               `json:"damage_type"`
+	HasHit      bool                 `json:"has_hit"`
+	Spec        towers.ProjectileDef `json:"spec"`
+	Effects     []effects.Def        `json:"effects"`
+	DirX        float64              `json:"dir_x"`
+	DirY        float64              `json:"dir_y"`
+	Travelled   float64              `json:"travelled"`
+	MaxDistance float64              `json:"max_distance"`
+	HitEnemies  []int                `json:"hit_enemies"`
+	JumpsLeft   int                  `json:"jumps_left"`
+}
+
+// SavePath returns the save file location in the config directory.
+func SavePath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, SaveFileName), nil
+}
+
+// SaveExists reports whether a save file is present.
+func SaveExists() bool {
+	path, err := SavePath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// DeleteSave removes the save file if there is one.
+func DeleteSave() error {
+	path, err := SavePath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// LoadSave reads and migrates the save file.
+func LoadSave() (*SaveFile, error) {
+	path, err := SavePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s SaveFile
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("save decode: %w", err)
+	}
+	return migrateSave(&s)
+}
+
+func migrateSave(s *SaveFile) (*SaveFile, error) {
+	// Future: bump SaveVersion and migrate old fields here
+	if s.Version < 1 {
+		return nil, fmt.Errorf("save has unknown version %d", s.Version)
+	}
+	if s.Version > SaveVersion {
+		return nil, fmt.Errorf("save version %d is newer than this game supports (%d)", s.Version, SaveVersion)
+	}
+	return s, nil
+}
+
+// Save writes the run to the save file.
+func (g *Game) Save() error {
+	s, err := g.Snapshot()
+	if err != nil {
+		return err
+	}
+	path, err := SavePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	log.Printf("DEBUG: Run saved to %s (map=%s wave=%d)", path, s.MapID, g.GetCurrentWave())
+	return nil
+}
+
+// CanSave reports whether the run is in progress on a data-driven map.
+func (g *Game) CanSave() bool {
+	if g.Map == nil || g.Wave == nil || g.playback != nil {
+		return false
+	}
+	switch g.Manager.State {
+	case StateMenu, StateWon, StateLost:
+		return false
+	}
+	return true
+}
+
+// Snapshot captures the run as a SaveFile.
+func (g *Game) Snapshot() (*SaveFile, error) {
+	if !g.CanSave() {
+		return nil, errors.New("no run in progress to save")
+	}
+
+	s := &SaveFile{
+		Version:     SaveVersion,
+		GameVersion: Version,
+		SavedAt:     time.Now().UTC().Format(time.RFC3339),
+		MapID:       g.Map.ID,
+		Options: savedOptions{
+			Endless:    g.Options.Endless,
+			Seed:       g.Options.Seed,
+			Difficulty: g.Options.Difficulty,
+		},
+		Tick:   g.Tick,
+		Money:  g.Money,
+		BaseHP: g.Base.HP,
+		Speed:  g.Speed,
+		Score: savedScore{
+			Points:        g.Score.Points,
+			EnemiesKilled: g.Score.EnemiesKilled,
+			WavesCleared:  g.Score.WavesCleared,
+		},
+		Stats: g.Stats,
+		Difficulty: savedDifficulty{
+			SpeedMultiplier:    g.Difficulty.SpeedMultiplier,
+			SpawnMultiplier:    g.Difficulty.SpawnMultiplier,
+			CountBonus:         g.Difficulty.CountBonus,
+			HPMultiplier:       g.Difficulty.HPMultiplier,
+			CountMultiplier:    g.Difficulty.CountMultiplier,
+			IntervalMultiplier: g.Difficulty.IntervalMultiplier,
+			RewardMultiplier:   g.Difficulty.RewardMultiplier,
+		},
+		Manager: savedManager{
+			State:          savedStatePreWave,
+			CurrentWave:    g.Manager.CurrentWave,
+			InterWaveTimer: g.Manager.InterWaveTimer,
+			RunTime:        g.Manager.RunTime,
+			BuildIndex:     g.Manager.BuildIndex,
+		},
+	}
+	if len(g.Wave.Active) > 0 {
+		s.Manager.State = savedStateInWave
+	}
+	state, err := g.rngSource.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("save rng: %w", err)
+	}
+	s.RNGState = state
+
+	s.Waves.Next = g.Wave.Next
+	s.Waves.Generated = append([]waves.WaveDef(nil), g.Wave.Waves[g.Wave.Scripted:]...)
+	for _, aw := range g.Wave.Active {
+		sw := savedActiveWave{Index: aw.Index}
+		for _, group := range aw.Groups {
+			sw.Groups = append(sw.Groups, savedGroup{
+				Def:        group.Def,
+				Count:      group.Count,
+				Interval:   group.Interval,
+				Spawned:    group.Spawned,
+				Timer:      group.Timer,
+				DelayTimer: group.DelayTimer,
+				Completed:  group.Completed,
+			})
+		}
+		s.Waves.Active = append(s.Waves.Active, sw)
+	}
+
+	enemyIndex := make(map[*entities.Enemy]int, len(g.Enemies))
+	for i, e := range g.Enemies {
+		enemyIndex[e] = i
+		s.Enemies = append(s.Enemies, snapshotEnemy(e))
+	}
+	indexOf := func(e *entities.Enemy) int {
+		if i, ok := enemyIndex[e]; ok {
+			return i
+		}
+		return -1
+	}
+
+	towerIndex := make(map[*entities.Tower]int, len(g.Towers))
+	for i, t := range g.Towers {
+		towerIndex[t] = i
+		s.Towers = append(s.Towers, savedTower{
+			TypeID:    t.TypeID,
+			X:         t.X,
+			Y:         t.Y,
+			Range:     t.Range,
+			Damage:    t.Damage,
+			FireRate:  t.FireRate,
+			Level:     t.Level,
+			Branch:    t.Branch,
+			Invested:  t.Invested,
+			Cooldown:  t.Cooldown,
+			Targeting: t.Targeting.String(),
+			Retarget:  t.Retarget.String(),
+			Target:    indexOf(t.Target),
+			Dealt:     t.DamageDealt,
+		})
+	}
+	for _, w := range g.Walls {
+		s.Walls = append(s.Walls, savedWall{Ax: w.Ax, Ay: w.Ay, Bx: w.Bx, By: w.By})
+	}
+	for _, p := range g.Projectiles {
+		sp := savedProjectile{
+			X:           p.X,
+			Y:           p.Y,
+			PrevX:       p.PrevX,
+			PrevY:       p.PrevY,
+			TargetX:     p.TargetX,
+			TargetY:     p.TargetY,
+			Target:      indexOf(p.TargetEnemy),
+			Source:      -1,
+			Speed:       p.Speed,
+			Damage:      p.Damage,
+			DamageType:  string(p.DamageType),
+			HasHit:      p.HasHit,
+			Spec:        p.Spec,
+			Effects:     p.Effects,
+			DirX:        p.DirX,
+			DirY:        p.DirY,
+			Travelled:   p.Travelled,
+			MaxDistance: p.MaxDistance,
+			JumpsLeft:   p.JumpsLeft,
+		}
+		sp.Spec.Kind = p.Kind.String()
+		if i, ok := towerIndex[p.Source]; ok {
+			sp.Source = i
+		}
+		for _, e := range p.HitEnemies {
+			sp.HitEnemies = append(sp.HitEnemies, indexOf(e))
+		}
+		s.Projectiles = append(s.Projectiles, sp)
+	}
+
+	return s, nil
+}
+
+func snapshotEnemy(e *entities.Enemy) savedEnemy {
+	se := savedEnemy{
+		TypeID:    e.EnemyTypeID,
+		X:         e.X,
+		Y:         e.Y,
+		Speed:     e.Speed,
+		HP:        e.HP,
+		MaxHP:     e.MaxHP,
+		Reward:    e.Reward,
+		PathIndex: e.PathIndex,
+		FieldSize: e.FieldSize,
+		WaveIndex: e.WaveIndex,
+		SpawnID:   e.SpawnID,
+		Boss:      e.Boss,
+		Phase:     e.Phase,
+		Shield:    e.Shield,
+		Flying:    e.Flying,
+	}
+	for _, p := range e.Path.Points {
+		se.Path = append(se.Path, mapdata.PointDef{X: p.X, Y: p.Y})
+	}
+	for _, st := range e.Effects {
+		se.Effects = append(se.Effects, savedStatus{
+			Kind:      st.Kind.String(),
+			Magnitude: st.Magnitude,
+			Remaining: st.Remaining,
+			Stacks:    st.Stacks,
+			MaxStacks: st.MaxStacks,
+		})
+	}
+	for _, a := range e.Abilities {
+		se.Abilities = append(se.Abilities, a.Timer)
+	}
+	return se
+}
+
+// RestoreGame rebuilds a run from a save file. Tower and enemy types that no longer exist are dropped.
+func RestoreGame(s *SaveFile) (*Game, error) {
+	m, err := mapdata.LoadMapByID(s.MapID)
+	if err != nil {
+		return nil, fmt.Errorf("load map %q: %w", s.MapID, err)
+	}
+	g := NewGameWithOptions(m, Options{
+		Endless:    s.Options.Endless,
+		Seed:       s.Options.Seed,
+		Difficulty: s.Options.Difficulty,
+	})
+
+	g.Money = s.Money
+	g.Base.HP = s.BaseHP
+	if s.Speed > 0 {
+		g.Speed = s.Speed
+	}
+	g.Score = Score{
+		Points:        s.Score.Points,
+		EnemiesKilled: s.Score.EnemiesKilled,
+		WavesCleared:  s.Score.WavesCleared,
+	}
+	g.Stats = s.Stats
+	g.Difficulty = Difficulty{
+		SpeedMultiplier:    s.Difficulty.SpeedMultiplier,
+		SpawnMultiplier:    s.Difficulty.SpawnMultiplier,
+		CountBonus:         s.Difficulty.CountBonus,
+		HPMultiplier:       s.Difficulty.HPMultiplier,
+		CountMultiplier:    s.Difficulty.CountMultiplier,
+		IntervalMultiplier: s.Difficulty.IntervalMultiplier,
+		RewardMultiplier:   s.Difficulty.RewardMultiplier,
+	}
+
+	// Waves: scripted ones come from the map, generated ones from the save. The generator is
+	// fast-forwarded so later endless waves continue the same sequence; older saves without an
+	// RNG state rely on this replaying the RNG too.
+	g.Wave.Waves = append(g.Wave.Waves[:g.Wave.Scripted], s.Waves.Generated...)
+	if g.Options.Endless {
+		g.seedRNG()
+		g.Generator = g.newGenerator()
+		for i := range s.Waves.Generated {
+			g.Generator.Next(g.Wave.Scripted + i + 1)
+		}
+	}
+	if len(s.RNGState) > 0 {
+		if err := g.rngSource.UnmarshalBinary(s.RNGState); err != nil {
+			return nil, fmt.Errorf("restore rng: %w", err)
+		}
+	}
+	g.Tick = s.Tick
+	g.Wave.Next = s.Waves.Next
+	g.Wave.Active = nil
+	for _, sw := range s.Waves.Active {
+		aw := waves.ActiveWave{Index: sw.Index}
+		for _, sg := range sw.Groups {
+			aw.Groups = append(aw.Groups, waves.ActiveSpawnGroup{
+				Def:        sg.Def,
+				Count:      sg.Count,
+				Interval:   sg.Interval,
+				Spawned:    sg.Spawned,
+				Timer:      sg.Timer,
+				DelayTimer: sg.DelayTimer,
+				Completed:  sg.Completed,
+			})
+		}
+		g.Wave.Active = append(g.Wave.Active, aw)
+	}
+
+	for _, w := range s.Walls {
+		g.Walls = append(g.Walls, Wall{Ax: w.Ax, Ay: w.Ay, Bx: w.Bx, By: w.By})
+	}
+	g.RecomputeFlow()
+
+	// Enemies keep their save index (nil when their type is gone) until references are resolved.
+	restored := make([]*entities.Enemy, len(s.Enemies))
+	g.Enemies = nil
+	for i, se := range s.Enemies {
+		e := g.restoreEnemy(se)
+		if e == nil {
+			continue
+		}
+		restored[i] = e
+		g.addEnemy(e)
+	}
+	at := func(i int) *entities.Enemy {
+		if i < 0 || i >= len(restored) {
+			return nil
+		}
+		return restored[i]
+	}
+
+	// Towers likewise keep their save index for projectile sources.
+	towersBySave := make(map[int]*entities.Tower, len(s.Towers))
+	g.Towers = nil
+	for i, st := range s.Towers {
+		def := g.TowerDB.Get(st.TypeID)
+		if def == nil {
+			log.Printf("WARN: saved tower type %q not found, dropping", st.TypeID)
+			continue
+		}
+		t := entities.NewTower(st.X, st.Y, def)
+		t.Range = st.Range
+		t.Damage = st.Damage
+		t.FireRate = st.FireRate
+		t.Level = st.Level
+		t.Branch = st.Branch
+		t.Invested = st.Invested
+		t.Cooldown = st.Cooldown
+		t.Targeting, _ = towers.ParseTargetingMode(st.Targeting)
+		t.Retarget, _ = towers.ParseRetargetPolicy(st.Retarget)
+		t.Target = at(st.Target)
+		t.DamageDealt = st.Dealt
+		g.Towers = append(g.Towers, t)
+		towersBySave[i] = t
+	}
+	g.refreshFlow() // tile costs can depend on the restored towers and enemies
+
+	g.Projectiles = nil
+	for _, sp := range s.Projectiles {
+		kind, _ := towers.ParseProjectileKind(sp.Spec.Kind)
+		damageType, _ := damage.Parse(sp.DamageType)
+		p := &entities.Projectile{
+			X:           sp.X,
+			Y:           sp.Y,
+			PrevX:       sp.PrevX,
+			PrevY:       sp.PrevY,
+			TargetX:     sp.TargetX,
+			TargetY:     sp.TargetY,
+			TargetEnemy: at(sp.Target),
+			Source:      towersBySave[sp.Source],
+			Speed:       sp.Speed,
+			Damage:      sp.Damage,
+			DamageType:  damageType,
+			HasHit:      sp.HasHit,
+			Kind:        kind,
+			Spec:        sp.Spec,
+			Effects:     sp.Effects,
+			DirX:        sp.DirX,
+			DirY:        sp.DirY,
+			Travelled:   sp.Travelled,
+			MaxDistance: sp.MaxDistance,
+			JumpsLeft:   sp.JumpsLeft,
+		}
+		for _, i := range sp.HitEnemies {
+			if e := at(i); e != nil {
+				p.HitEnemies = append(p.HitEnemies, e)
+			}
+		}
+		g.Projectiles = append(g.Projectiles, p)
+	}
+
+	g.Manager.CurrentWave = s.Manager.CurrentWave
+	g.Manager.InterWaveTimer = s.Manager.InterWaveTimer
+	g.Manager.RunTime = s.Manager.RunTime
+	g.Manager.BuildIndex = s.Manager.BuildIndex
+	g.Manager.State = StatePreWave
+	if s.Manager.State == savedStateInWave {
+		g.Manager.State = StateInWave
+		g.Manager.TogglePause()
+	}
+
+	log.Printf("DEBUG: Run restored (map=%s wave=%d towers=%d enemies=%d)", s.MapID, g.GetCurrentWave(), len(g.Towers), len(g.Enemies))
+	return g, nil
+}
+
+// restoreEnemy rebuilds a saved enemy from its definition, or returns nil if the type is gone.
+func (g *Game) restoreEnemy(se savedEnemy) *entities.Enemy {
+	def := g.EnemyDB.Get(se.TypeID)
+	if def == nil {
+		log.Printf("WARN: saved enemy type %q not found, dropping", se.TypeID)
+		return nil
+	}
+	var path mapdata.Path
+	for _, p := range se.Path {
+		path.Points = append(path.Points, mapdata.Point{X: p.X, Y: p.Y})
+	}
+	if len(path.Points) == 0 {
+		path = g.Path
+	}
+
+	e := entities.NewEnemyFromDef(def, path)
+	e.RestorePhase(se.Phase)
+	e.X, e.Y = se.X, se.Y
+	e.Speed = se.Speed
+	e.HP, e.MaxHP = se.HP, se.MaxHP
+	e.Reward = se.Reward
+	e.PathIndex = se.PathIndex
+	e.FieldSize = max(se.FieldSize, 1)
+	e.WaveIndex = se.WaveIndex
+	e.SpawnID = se.SpawnID
+	e.Boss = se.Boss
+	e.Shield = se.Shield
+	e.Flying = se.Flying
+	for _, st := range se.Effects {
+		kind, err := effects.ParseKind(st.Kind)
+		if err != nil {
+			continue
+		}
+		e.Effects = append(e.Effects, effects.Status{
+			Kind:      kind,
+			Magnitude: st.Magnitude,
+			Remaining: st.Remaining,
+			Stacks:    st.Stacks,
+			MaxStacks: st.MaxStacks,
+		})
+	}
+	for i := range e.Abilities {
+		if i < len(se.Abilities) {
+			e.Abilities[i].Timer = se.Abilities[i]
+		}
+	}
+	return e
+}
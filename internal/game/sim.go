@@ -0,0 +1,66 @@
+package game
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+)
+
+// SimStep is the fixed simulation step in seconds. Higher speed settings run more steps per
+// frame rather than longer ones.
+const SimStep = 1.0 / 20
+
+// seedRNG resets the simulation RNG and clock from Options.Seed.
+func (g *Game) seedRNG() {
+	g.rngSource = rand.NewPCG(uint64(g.Options.Seed), 0)
+	g.RNG = rand.New(g.rngSource)
+	g.Tick = 0
+	g.accumulator = 0
+}
+
+// Checksum hashes the simulation state. Two runs with the same map, options and inputs have
+// the same checksum at the same tick.
+func (g *Game) Checksum() uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	putInt := func(v int64) {
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		h.Write(buf[:])
+	}
+	putFloat := func(v float64) {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+		h.Write(buf[:])
+	}
+
+	putInt(int64(g.Tick))
+	putInt(int64(g.Manager.State))
+	putInt(int64(g.Manager.CurrentWave))
+	putFloat(g.Manager.InterWaveTimer)
+	putInt(int64(g.Money))
+	putInt(int64(g.Base.HP))
+	putInt(int64(g.Score.Points))
+	putInt(int64(g.Score.EnemiesKilled))
+	if g.Wave != nil {
+		putInt(int64(g.Wave.Next))
+		putInt(int64(len(g.Wave.Active)))
+	}
+	for _, e := range g.Enemies {
+		h.Write([]byte(e.EnemyTypeID))
+		putFloat(e.X)
+		putFloat(e.Y)
+		putFloat(e.HP)
+		putFloat(e.Shield)
+		putInt(int64(e.PathIndex))
+	}
+	for _, t := range g.Towers {
+		putInt(int64(t.X))
+		putInt(int64(t.Y))
+		putFloat(t.Cooldown)
+	}
+	for _, p := range g.Projectiles {
+		putFloat(p.X)
+		putFloat(p.Y)
+	}
+	return h.Sum64()
+}
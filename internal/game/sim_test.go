@@ -0,0 +1,137 @@
+package game
+
+import (
+	"io"
+	"log"
+	"testing"
+
+	"terminal-td/internal/content"
+	mapdata "terminal-td/internal/map"
+)
+
+// maxTestTicks bounds a run in case it never ends.
+const maxTestTicks = 100000
+
+// recordRun plays classic at one step per frame, issuing commands at fixed ticks, until the run
+// ends. It returns the recording with the final tick and checksum filled in.
+func recordRun(t *testing.T) *Replay {
+	t.Helper()
+	m, err := mapdata.LoadMapByID("classic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGameWithOptions(m, Options{Seed: 42})
+	g.Begin()
+	g.StartRecording()
+
+	// Towers on the first buildable tiles next to the path, so they see action.
+	var spots [][2]int
+	for y := 0; y < g.Grid.Height && len(spots) < 4; y++ {
+		for x := 0; x < g.Grid.Width && len(spots) < 4; x++ {
+			if g.CanPlaceTower(x, y) && y+1 < g.Grid.Height && !g.Grid.Buildable(x, y+1) {
+				spots = append(spots, [2]int{x, y})
+			}
+		}
+	}
+	if len(spots) < 4 {
+		t.Fatalf("found %d tower spots, want 4", len(spots))
+	}
+	commands := map[uint64]Command{
+		1:    {Type: CmdPlaceTower, X: spots[0][0], Y: spots[0][1], Tower: "basic"},
+		37:   {Type: CmdPlaceTower, X: spots[1][0], Y: spots[1][1], Tower: "frost"},
+		61:   {Type: CmdCallWave},
+		250:  {Type: CmdSetSpeed, Speed: 2},
+		333:  {Type: CmdCycleTargeting, X: spots[0][0], Y: spots[0][1]},
+		401:  {Type: CmdPlaceTower, X: spots[2][0], Y: spots[2][1], Tower: "rapid"},
+		777:  {Type: CmdUpgradeTower, X: spots[0][0], Y: spots[0][1]},
+		1203: {Type: CmdPlaceTower, X: spots[3][0], Y: spots[3][1], Tower: "sniper"},
+		1500: {Type: CmdSellTower, X: spots[1][0], Y: spots[1][1]},
+	}
+	g.Speed = 1
+	for g.stepping() && g.Tick < maxTestTicks {
+		if c, ok := commands[g.Tick]; ok {
+			g.Apply(c)
+		}
+		g.Speed = 1 // one step per frame, whatever speed the player picked
+		g.Update(SimStep)
+	}
+	if g.stepping() {
+		t.Fatalf("run did not end within %d ticks", maxTestTicks)
+	}
+	if len(g.Recording.Commands) < 5 {
+		t.Fatalf("only %d commands took effect", len(g.Recording.Commands))
+	}
+	r := g.Recording
+	r.FinalTick = g.Tick
+	r.Checksum = g.Checksum()
+	return r
+}
+
+// TestStepDeterminism replays the same seed and commands at different frame rates and speeds and
+// expects the same final tick and checksum: frame timing must never reach the simulation.
+func TestStepDeterminism(t *testing.T) {
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(io.Discard)
+	content.UseBuiltinOnly()
+
+	r := recordRun(t)
+	t.Logf("recorded %d commands over %d ticks", len(r.Commands), r.FinalTick)
+	cases := []struct {
+		name  string
+		dt    float64
+		speed float64
+	}{
+		{"1x 20fps", SimStep, 1},
+		{"1x 60fps", 1.0 / 60, 1},
+		{"1x uneven frames", 0.037, 1},
+		{"4x 30fps", 1.0 / 30, 4},
+		{"4x 7fps", 1.0 / 7, 4},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := NewReplayGame(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			g.Speed = tc.speed
+			for g.stepping() && g.Tick < maxTestTicks {
+				g.Update(tc.dt)
+			}
+			if g.Tick != r.FinalTick {
+				t.Errorf("ended at tick %d, want %d", g.Tick, r.FinalTick)
+			}
+			if sum := g.Checksum(); sum != r.Checksum {
+				t.Errorf("checksum %x, want %x", sum, r.Checksum)
+			}
+			if _, _, result := g.ReplayProgress(); result != ReplayVerified {
+				t.Errorf("replay result %q, want %q", result, ReplayVerified)
+			}
+		})
+	}
+}
+
+// TestReplayRefusesOtherContent expects playback to be refused when the mods or the data the
+// replay was recorded with differ from the game's.
+func TestReplayRefusesOtherContent(t *testing.T) {
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(io.Discard)
+	content.UseBuiltinOnly()
+
+	r := recordRun(t)
+	if r.ContentHash == 0 {
+		t.Fatal("recording has no content hash")
+	}
+	if _, err := NewReplayGame(r); err != nil {
+		t.Fatalf("same content: %v", err)
+	}
+	otherData := *r
+	otherData.ContentHash++
+	if _, err := NewReplayGame(&otherData); err == nil {
+		t.Error("replay with another content hash was accepted")
+	}
+	otherMods := *r
+	otherMods.Mods = []ModRef{{ID: "bigger-bosses", Version: "1.0.0"}}
+	if _, err := NewReplayGame(&otherMods); err == nil {
+		t.Error("replay recorded with a mod that is not enabled was accepted")
+	}
+}
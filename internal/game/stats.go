@@ -0,0 +1,25 @@
+package game
+
+import "terminal-td/internal/entities"
+
+// Stats are per-run counters for balance reports. Tower damage is kept on each tower
+// (entities.Tower.DamageDealt).
+type Stats struct {
+	BaseHPLost   []int          `json:"base_hp_lost"`   // base HP lost per wave index
+	LeaksBySpawn map[string]int `json:"leaks_by_spawn"` // enemies that reached the base, by spawn ID
+	EffectDamage float64        `json:"effect_damage"`  // burn and poison damage, which is not credited to a tower
+}
+
+func (s *Stats) recordLeak(e *entities.Enemy) {
+	if s.LeaksBySpawn == nil {
+		s.LeaksBySpawn = make(map[string]int)
+	}
+	s.LeaksBySpawn[e.SpawnID]++
+	if e.WaveIndex < 0 {
+		return
+	}
+	for len(s.BaseHPLost) <= e.WaveIndex {
+		s.BaseHPLost = append(s.BaseHPLost, 0)
+	}
+	s.BaseHPLost[e.WaveIndex] += e.LeakDamage()
+}
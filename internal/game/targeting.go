@@ -0,0 +1,109 @@
+package game
+
+import (
+	"log"
+	"math"
+
+	"terminal-td/internal/entities"
+	"terminal-td/internal/flow"
+	"terminal-td/internal/towers"
+)
+
+// RemainingDistance returns the enemy's flow-field distance to the base (Inf when unknown).
+// Flyers ignore the field, so theirs is the straight-line distance.
+func (g *Game) RemainingDistance(e *entities.Enemy) float64 {
+	if e.Flying {
+		return math.Hypot(float64(g.Base.X)-e.X, float64(g.Base.Y)-e.Y)
+	}
+	if g.FlowField == nil {
+		return flow.Inf
+	}
+	dist, _ := g.enemyField(e).AtFloat(e.X, e.Y)
+	return dist
+}
+
+// EnemyAt returns the living enemy covering tile (x, y), preferring bosses, or nil.
+func (g *Game) EnemyAt(x, y int) *entities.Enemy {
+	var found *entities.Enemy
+	for _, e := range g.Enemies {
+		if e.HP <= 0 || !e.Covers(x, y) {
+			continue
+		}
+		if e.Boss {
+			return e
+		}
+		if found == nil {
+			found = e
+		}
+	}
+	return found
+}
+
+// selectTarget returns the best enemy in range for the tower's targeting mode, or nil.
+// Ties keep the earliest enemy in g.Enemies so selection is deterministic.
+func (g *Game) selectTarget(tower *entities.Tower) *entities.Enemy {
+	mode := tower.Targeting
+	if g.FlowField == nil && (mode == towers.TargetFirst || mode == towers.TargetLast) {
+		mode = towers.TargetClosest
+	}
+
+	var best *entities.Enemy
+	var bestScore float64
+
+	for _, enemy := range g.Enemies {
+		if !g.isEnemyInRange(tower, enemy) {
+			continue
+		}
+
+		// Higher score wins.
+		var score float64
+		switch mode {
+		case towers.TargetFirst:
+			score = -g.RemainingDistance(enemy)
+		case towers.TargetLast:
+			score = g.RemainingDistance(enemy)
+		case towers.TargetStrongest:
+			score = enemy.HP
+		case towers.TargetWeakest:
+			score = -enemy.HP
+		case towers.TargetFastest:
+			score = enemy.Speed
+		default:
+			score = -tower.DistanceTo(enemy.Center())
+		}
+
+		if best == nil || score > bestScore {
+			best = enemy
+			bestScore = score
+		}
+	}
+
+	return best
+}
+
+// CycleTargeting switches the tower at (x,y) to its next targeting mode and drops its current target.
+func (g *Game) CycleTargeting(x, y int) bool {
+	tower := g.GetTowerAt(x, y)
+	if tower == nil {
+		return false
+	}
+	tower.Targeting = tower.Targeting.Next()
+	tower.Target = nil
+	log.Printf("DEBUG: Tower at (%d,%d) targeting set to %s", x, y, tower.Targeting)
+	return true
+}
+
+// ToggleRetarget flips the tower at (x,y) between sticky and re-evaluate-each-shot targeting.
+func (g *Game) ToggleRetarget(x, y int) bool {
+	tower := g.GetTowerAt(x, y)
+	if tower == nil {
+		return false
+	}
+	if tower.Retarget == towers.RetargetSticky {
+		tower.Retarget = towers.RetargetEachShot
+	} else {
+		tower.Retarget = towers.RetargetSticky
+	}
+	log.Printf("DEBUG: Tower at (%d,%d) retarget policy set to %s", x, y, tower.Retarget)
+	return true
+}
@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"embed"
 	"fmt"
+	"log"
 	"strings"
+
+	"terminal-td/internal/content"
 )
 
 //go:embed data/*.json
@@ -12,40 +15,99 @@ var defaultMapFS embed.FS
 
 // MapInfo holds map metadata for selection.
 type MapInfo struct {
-	ID   string
-	Name string
+	ID     string
+	Name   string
+	Source string // "" for built-in maps, otherwise the mod name or "custom"
 }
 
-// ListMaps returns all available embedded maps.
+// ListMaps returns the embedded maps followed by maps from mods and the content directory. A map
+// with the ID of an earlier one replaces it in place.
 func ListMaps() ([]MapInfo, error) {
-	entries, err := defaultMapFS.ReadDir("data")
+	builtin, err := embeddedMaps()
 	if err != nil {
-		return nil, fmt.Errorf("read maps dir: %w", err)
+		return nil, err
 	}
 	var maps []MapInfo
-	for _, e := range entries {
-		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
-			continue
-		}
-		data, err := defaultMapFS.ReadFile("data/" + e.Name())
+	for _, m := range builtin {
+		maps = append(maps, MapInfo{ID: m.ID, Name: m.Name})
+	}
+	for _, f := range content.Files(content.MapsDir) {
+		m, err := loadContentMap(f)
 		if err != nil {
+			log.Printf("DEBUG: skipping map %s: %v", f.Path, err)
 			continue
 		}
-		m, err := LoadMapBytes(data)
-		if err != nil {
-			continue
+		info := MapInfo{ID: m.ID, Name: m.Name, Source: f.Source}
+		replaced := false
+		for i := range maps {
+			if maps[i].ID == m.ID {
+				log.Printf("DEBUG: map %q from %s overrides %s", m.ID, f.Path, sourceName(maps[i].Source))
+				maps[i] = info
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			maps = append(maps, info)
 		}
-		maps = append(maps, MapInfo{ID: m.ID, Name: m.Name})
 	}
 	return maps, nil
 }
 
-// LoadMapByID loads a map by its ID from embedded maps.
+// LoadMapByID loads a map by its ID, preferring the content directory, then mods in reverse load
+// order, then embedded maps.
 func LoadMapByID(id string) (*GameMap, error) {
+	files := content.Files(content.MapsDir)
+	for i := len(files) - 1; i >= 0; i-- {
+		m, err := loadContentMap(files[i])
+		if err != nil {
+			continue
+		}
+		if m.ID == id {
+			return m, nil
+		}
+	}
+	builtin, err := embeddedMaps()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range builtin {
+		if m.ID == id {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("map %q not found", id)
+}
+
+func loadContentMap(f content.File) (*GameMap, error) {
+	data, err := f.Read()
+	if err != nil {
+		return nil, err
+	}
+	return LoadMapBytes(data)
+}
+
+// embeddedMaps loads every built-in map, skipping (and logging) any that fail to parse.
+// IsBuiltin reports whether id is the ID of a map built into the game.
+func IsBuiltin(id string) bool {
+	maps, err := embeddedMaps()
+	if err != nil {
+		return false
+	}
+	for _, m := range maps {
+		if m.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func embeddedMaps() ([]*GameMap, error) {
 	entries, err := defaultMapFS.ReadDir("data")
 	if err != nil {
 		return nil, fmt.Errorf("read maps dir: %w", err)
 	}
+	var maps []*GameMap
 	for _, e := range entries {
 		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
 			continue
@@ -56,16 +118,22 @@ func LoadMapByID(id string) (*GameMap, error) {
 		}
 		m, err := LoadMapBytes(data)
 		if err != nil {
+			log.Printf("DEBUG: skipping map %s: %v", e.Name(), err)
 			continue
 		}
-		if m.ID == id {
-			return m, nil
-		}
+		maps = append(maps, m)
 	}
-	return nil, fmt.Errorf("map %q not found", id)
+	return maps, nil
+}
+
+func sourceName(source string) string {
+	if source == "" {
+		return "built-in"
+	}
+	return source
 }
 
-// DefaultMap returns the built-in classic map (same layout as legacy hardcoded map).
+// DefaultMap returns the classic map (same layout as legacy hardcoded map).
 func DefaultMap() (*GameMap, error) {
 	return LoadMapByID("classic")
 }
@@ -7,12 +7,54 @@ const (
 	PathTile
 	SpawnTile
 	BaseTile
+	RockTile       // blocks enemies and towers
+	WaterTile      // blocks enemies and towers
+	BuildTile      // a tower spot; maps with build tiles only allow towers there
+	MudTile        // walkable; slows enemies
+	IceTile        // walkable; speeds enemies up
+	HighGroundTile // buildable; towers on it get extra range
 )
 
+// Terrain effects.
+const (
+	MudSpeedFactor       = 0.5 // enemies on mud move at half speed
+	IceSpeedFactor       = 1.5
+	HighGroundRangeBonus = 1.5 // tiles of range added to towers built on high ground
+)
+
+// Walkable reports whether enemies can walk on the tile.
+func (t TileType) Walkable() bool {
+	switch t {
+	case PathTile, SpawnTile, BaseTile, MudTile, IceTile:
+		return true
+	}
+	return false
+}
+
+// SpeedFactor scales the speed of enemies walking on the tile.
+func (t TileType) SpeedFactor() float64 {
+	switch t {
+	case MudTile:
+		return MudSpeedFactor
+	case IceTile:
+		return IceSpeedFactor
+	}
+	return 1
+}
+
+// RangeBonus is the range added to a tower built on the tile.
+func (t TileType) RangeBonus() float64 {
+	if t == HighGroundTile {
+		return HighGroundRangeBonus
+	}
+	return 0
+}
+
 type Grid struct {
-	Width  int
-	Height int
-	Tiles  [][]TileType
+	Width     int
+	Height    int
+	Tiles     [][]TileType
+	BuildOnly bool // towers may only stand on BuildTile tiles
 }
 
 func NewGrid(w, h int) *Grid {
@@ -28,3 +70,17 @@ func NewGrid(w, h int) *Grid {
 		Tiles:  tiles,
 	}
 }
+
+// Buildable reports whether a tower may stand on tile (x, y), ignoring towers already there.
+func (g *Grid) Buildable(x, y int) bool {
+	if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
+		return false
+	}
+	switch g.Tiles[y][x] {
+	case Empty:
+		return !g.BuildOnly
+	case BuildTile, HighGroundTile:
+		return true
+	}
+	return false
+}
@@ -8,13 +8,26 @@ import (
 	"os"
 )
 
-// LoadMap reads a map definition from r and builds a GameMap (grid from paths/spawns/base).
+// LoadMap reads a map definition from r and builds a GameMap, from the tile layer for tile maps
+// and from paths/spawns/base otherwise.
 func LoadMap(r io.Reader) (*GameMap, error) {
 	var def MapDef
 	if err := json.NewDecoder(r).Decode(&def); err != nil {
 		return nil, fmt.Errorf("map decode: %w", err)
 	}
-	return buildGameMap(&def)
+	if problems := def.Validate(); len(problems) > 0 {
+		return nil, problems[0]
+	}
+	build := buildGameMap
+	if len(def.Tiles) > 0 {
+		build = buildTileMap
+	}
+	m, err := build(&def)
+	if err != nil {
+		return nil, err
+	}
+	m.Pathing = def.Pathing
+	return m, nil
 }
 
 // LoadMapFile reads a map from a JSON file path.
@@ -27,18 +40,10 @@ func LoadMapFile(path string) (*GameMap, error) {
 	return LoadMap(f)
 }
 
+// buildGameMap builds a waypoint map that passed Validate.
 func buildGameMap(def *MapDef) (*GameMap, error) {
-	if def.Grid.Width <= 0 || def.Grid.Height <= 0 {
-		return nil, fmt.Errorf("invalid grid size %dx%d", def.Grid.Width, def.Grid.Height)
-	}
 	spawnByID := make(map[string]SpawnPoint)
 	for _, s := range def.Spawns {
-		if s.ID == "" {
-			return nil, fmt.Errorf("spawn with empty id")
-		}
-		if _, ok := spawnByID[s.ID]; ok {
-			return nil, fmt.Errorf("duplicate spawn id %q", s.ID)
-		}
 		spawnByID[s.ID] = SpawnPoint{ID: s.ID, X: s.X, Y: s.Y}
 	}
 
@@ -46,21 +51,9 @@ func buildGameMap(def *MapDef) (*GameMap, error) {
 	grid := NewGrid(def.Grid.Width, def.Grid.Height)
 
 	for _, pd := range def.Paths {
-		if pd.SpawnID == "" {
-			return nil, fmt.Errorf("path with empty spawn_id")
-		}
-		if _, ok := spawnByID[pd.SpawnID]; !ok {
-			return nil, fmt.Errorf("path spawn_id %q not in spawns", pd.SpawnID)
-		}
-		if len(pd.Points) < 2 {
-			return nil, fmt.Errorf("path for spawn %q has fewer than 2 points", pd.SpawnID)
-		}
 		points := make([]Point, len(pd.Points))
 		for i, p := range pd.Points {
 			points[i] = Point{X: p.X, Y: p.Y}
-			if p.X < 0 || p.X >= def.Grid.Width || p.Y < 0 || p.Y >= def.Grid.Height {
-				return nil, fmt.Errorf("path point (%d,%d) out of bounds", p.X, p.Y)
-			}
 		}
 		path := Path{Points: points}
 		sp := spawnByID[pd.SpawnID]
@@ -72,19 +65,13 @@ func buildGameMap(def *MapDef) (*GameMap, error) {
 		}
 		ApplyPathSegmentsOnly(grid, path)
 	}
+	applyTerrain(grid, def.Terrain)
 	for _, s := range def.Spawns {
 		sp := spawnByID[s.ID]
 		grid.Tiles[sp.Y][sp.X] = SpawnTile
 	}
 	grid.Tiles[def.Base.Y][def.Base.X] = BaseTile
 
-	if def.Base.X < 0 || def.Base.X >= def.Grid.Width || def.Base.Y < 0 || def.Base.Y >= def.Grid.Height {
-		return nil, fmt.Errorf("base (%d,%d) out of bounds", def.Base.X, def.Base.Y)
-	}
-	if def.Base.HP <= 0 {
-		return nil, fmt.Errorf("base hp must be positive, got %d", def.Base.HP)
-	}
-
 	spawns := make([]SpawnPoint, 0, len(def.Spawns))
 	for _, s := range def.Spawns {
 		spawns = append(spawns, spawnByID[s.ID])
@@ -0,0 +1,357 @@
+package mapdata
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"terminal-td/internal/content"
+)
+
+// Tile names used in a tile map legend. A spawn is "spawn:<id>"; a bare "spawn" has the ID
+// DefaultSpawnID.
+const (
+	TileNameEmpty = "empty"
+	TileNamePath  = "path"
+	TileNameSpawn = "spawn"
+	TileNameBase  = "base"
+	TileNameRock  = "rock"
+	TileNameWater = "water"
+	TileNameBuild = "build"
+	TileNameMud   = "mud"
+	TileNameIce   = "ice"
+	TileNameHigh  = "high_ground"
+
+	DefaultSpawnID = "default"
+)
+
+var tileNames = map[string]TileType{
+	TileNameEmpty: Empty,
+	TileNamePath:  PathTile,
+	TileNameBase:  BaseTile,
+	TileNameRock:  RockTile,
+	TileNameWater: WaterTile,
+	TileNameBuild: BuildTile,
+	TileNameMud:   MudTile,
+	TileNameIce:   IceTile,
+	TileNameHigh:  HighGroundTile,
+}
+
+// DefaultLegend is the legend every tile map starts from; it matches how the game draws tiles.
+// A map's own legend adds characters or redefines these.
+var DefaultLegend = map[string]string{
+	".": TileNameEmpty,
+	"=": TileNamePath,
+	"S": TileNameSpawn,
+	"E": TileNameBase,
+	"#": TileNameRock,
+	"~": TileNameWater,
+	"+": TileNameBuild,
+	",": TileNameMud,
+	":": TileNameIce,
+	"^": TileNameHigh,
+}
+
+type legendEntry struct {
+	tile    TileType
+	spawnID string
+}
+
+// parseLegend merges legend over DefaultLegend. Bad entries are reported and left out.
+func parseLegend(legend map[string]string) (map[rune]legendEntry, []content.Problem) {
+	out := make(map[rune]legendEntry)
+	var problems []content.Problem
+	add := func(key, name string) {
+		path := fmt.Sprintf("$.legend[%q]", key)
+		if utf8.RuneCountInString(key) != 1 {
+			problems = append(problems, content.Problemf(path, "legend key %q must be a single character", key))
+			return
+		}
+		r, _ := utf8.DecodeRuneInString(key)
+		if name == TileNameSpawn {
+			name = TileNameSpawn + ":" + DefaultSpawnID
+		}
+		if id, ok := strings.CutPrefix(name, TileNameSpawn+":"); ok {
+			if id == "" {
+				problems = append(problems, content.Problemf(path, "legend %q: spawn with empty id", key))
+				return
+			}
+			out[r] = legendEntry{tile: SpawnTile, spawnID: id}
+			return
+		}
+		t, ok := tileNames[name]
+		if !ok {
+			problems = append(problems, content.Problemf(path, "legend %q: unknown tile %q", key, name))
+			return
+		}
+		out[r] = legendEntry{tile: t}
+	}
+	for key, name := range DefaultLegend {
+		add(key, name)
+	}
+	keys := make([]string, 0, len(legend))
+	for key := range legend {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		add(key, legend[key])
+	}
+	return out, problems
+}
+
+// tileLayer is what parseTiles reads from a tile layer.
+type tileLayer struct {
+	grid         *Grid
+	spawns       []SpawnPoint
+	spawnChars   map[string]rune
+	baseX, baseY int
+}
+
+// parseTiles reads the tile layer of def and reports every problem with it, with JSON paths from
+// the file's root. The layer is nil when the tiles cannot be read at all.
+func parseTiles(def *MapDef) (*tileLayer, []content.Problem) {
+	var problems []content.Problem
+	add := func(path, format string, args ...any) {
+		problems = append(problems, content.Problemf(path, format, args...))
+	}
+	if len(def.Spawns) > 0 {
+		add("$.spawns", "tile maps take spawns from the tile layer; remove \"spawns\"")
+	}
+	if len(def.Paths) > 0 {
+		add("$.paths", "tile maps take paths from the tile layer; remove \"paths\"")
+	}
+	if len(def.Terrain) > 0 {
+		add("$.terrain", "tile maps draw terrain in the tile layer; remove \"terrain\"")
+	}
+	if def.Base.HP <= 0 {
+		add("$.base.hp", "base hp must be positive, got %d", def.Base.HP)
+	}
+	legend, legendProblems := parseLegend(def.Legend)
+	problems = append(problems, legendProblems...)
+	h := len(def.Tiles)
+	w := utf8.RuneCountInString(def.Tiles[0])
+	if w == 0 {
+		add("$.tiles[0]", "tiles row 0 is empty")
+		return nil, problems
+	}
+	if (def.Grid.Width != 0 || def.Grid.Height != 0) && (def.Grid.Width != w || def.Grid.Height != h) {
+		add("$.grid", "grid %dx%d does not match the %dx%d tile layer", def.Grid.Width, def.Grid.Height, w, h)
+	}
+
+	layer := &tileLayer{grid: NewGrid(w, h), spawnChars: make(map[string]rune), baseX: -1, baseY: -1}
+	spawnAt := make(map[string][2]int)
+	for y, row := range def.Tiles {
+		path := fmt.Sprintf("$.tiles[%d]", y)
+		if n := utf8.RuneCountInString(row); n != w {
+			add(path, "tiles row %d has %d columns, want %d", y, n, w)
+			continue
+		}
+		x := 0
+		for _, r := range row {
+			e, ok := legend[r]
+			if !ok {
+				add(path, "tiles row %d column %d: %q is not in the legend", y, x, r)
+				x++
+				continue
+			}
+			layer.grid.Tiles[y][x] = e.tile
+			switch e.tile {
+			case SpawnTile:
+				if at, dup := spawnAt[e.spawnID]; dup {
+					add(path, "spawn %q is on both (%d,%d) and (%d,%d); give each spawn its own legend character", e.spawnID, at[0], at[1], x, y)
+					break
+				}
+				spawnAt[e.spawnID] = [2]int{x, y}
+				layer.spawnChars[e.spawnID] = r
+				layer.spawns = append(layer.spawns, SpawnPoint{ID: e.spawnID, X: x, Y: y})
+			case BaseTile:
+				if layer.baseX >= 0 {
+					add(path, "more than one base tile: (%d,%d) and (%d,%d)", layer.baseX, layer.baseY, x, y)
+					break
+				}
+				layer.baseX, layer.baseY = x, y
+			case BuildTile:
+				layer.grid.BuildOnly = true
+			}
+			x++
+		}
+	}
+	if layer.baseX < 0 {
+		add("$.tiles", "tile layer has no base tile")
+	} else if (def.Base.X != 0 || def.Base.Y != 0) && (def.Base.X != layer.baseX || def.Base.Y != layer.baseY) {
+		add("$.base", "base (%d,%d) does not match the base tile at (%d,%d)", def.Base.X, def.Base.Y, layer.baseX, layer.baseY)
+	}
+	if len(layer.spawns) == 0 {
+		add("$.tiles", "tile layer has no spawn tile")
+	}
+	return layer, problems
+}
+
+// buildTileMap builds a GameMap from the tile layer of a map that passed Validate. Tile maps have
+// no waypoints: each spawn gets a path from the spawn to the base so enemies start there, and the
+// flow field does the routing.
+func buildTileMap(def *MapDef) (*GameMap, error) {
+	layer, problems := parseTiles(def)
+	if len(problems) > 0 {
+		return nil, problems[0]
+	}
+	baseX, baseY := layer.baseX, layer.baseY
+	paths := make(map[string]Path)
+	for _, s := range layer.spawns {
+		paths[s.ID] = Path{Points: []Point{{X: s.X, Y: s.Y}, {X: baseX, Y: baseY}}}
+	}
+
+	log.Printf("map loaded: id=%s name=%q tiles=%dx%d spawns=%d base=(%d,%d) hp=%d build_only=%v",
+		def.ID, def.Name, layer.grid.Width, layer.grid.Height, len(layer.spawns), baseX, baseY, def.Base.HP, layer.grid.BuildOnly)
+
+	return &GameMap{
+		ID:         def.ID,
+		Name:       def.Name,
+		Grid:       layer.grid,
+		Spawns:     layer.spawns,
+		Paths:      paths,
+		Base:       BaseInfo{X: baseX, Y: baseY, HP: def.Base.HP},
+		SpawnChars: layer.spawnChars,
+	}, nil
+}
+
+// TerrainTile returns the tile a terrain area may paint by name: any tile but spawns and the base.
+func TerrainTile(name string) (TileType, bool) {
+	tile, ok := tileNames[name]
+	if !ok || tile == BaseTile || tile == SpawnTile {
+		return Empty, false
+	}
+	return tile, true
+}
+
+// applyTerrain paints the terrain areas of a waypoint map that passed Validate onto grid. Call it
+// before spawns and the base are placed so they stay on top.
+func applyTerrain(grid *Grid, terrain []TerrainDef) {
+	for _, t := range terrain {
+		tile, _ := TerrainTile(t.Tile)
+		w, h := max(t.Width, 1), max(t.Height, 1)
+		for y := t.Y; y < t.Y+h; y++ {
+			for x := t.X; x < t.X+w; x++ {
+				grid.Tiles[y][x] = tile
+			}
+		}
+		if tile == BuildTile {
+			grid.BuildOnly = true
+		}
+	}
+}
+
+// TileDef returns m as a tile map definition, which LoadMap reads back into the same grid,
+// spawns and base. Waypoint maps can be converted this way too.
+func (m *GameMap) TileDef() *MapDef {
+	chars := make(map[TileType]rune)
+	for key, name := range DefaultLegend {
+		if t, ok := tileNames[name]; ok {
+			chars[t], _ = utf8.DecodeRuneInString(key)
+		}
+	}
+
+	legend := make(map[string]string)
+	spawnChar := make(map[[2]int]rune)
+	used := make(map[rune]bool)
+	for key := range DefaultLegend {
+		r, _ := utf8.DecodeRuneInString(key)
+		used[r] = true
+	}
+	// Spawns are lettered in ID order, keeping the characters of the map's own tile layer, so
+	// saving a loaded tile map again gives the same JSON.
+	spawns := append([]SpawnPoint(nil), m.Spawns...)
+	sort.Slice(spawns, func(i, j int) bool { return spawns[i].ID < spawns[j].ID })
+	const defaultSpawnKey = 'S' // DefaultLegend's character for the default spawn
+	assigned := make(map[string]bool)
+	assign := func(s SpawnPoint, r rune) {
+		used[r] = true
+		assigned[s.ID] = true
+		spawnChar[[2]int{s.X, s.Y}] = r
+		if !(r == defaultSpawnKey && s.ID == DefaultSpawnID) {
+			legend[string(r)] = TileNameSpawn + ":" + s.ID
+		}
+	}
+	spawnKeyFree := true
+	for _, s := range spawns {
+		r, ok := m.SpawnChars[s.ID]
+		if !ok {
+			continue
+		}
+		switch {
+		case r == defaultSpawnKey && spawnKeyFree:
+			spawnKeyFree = false
+			assign(s, r)
+		case !used[r]:
+			assign(s, r)
+		}
+	}
+	for _, s := range spawns {
+		if assigned[s.ID] {
+			continue
+		}
+		if s.ID == DefaultSpawnID && len(spawns) == 1 && spawnKeyFree {
+			spawnKeyFree = false
+			assign(s, defaultSpawnKey)
+			continue
+		}
+		assign(s, pickSpawnChar(s.ID, used))
+	}
+
+	rows := make([]string, m.Grid.Height)
+	for y := range rows {
+		var b strings.Builder
+		for x := 0; x < m.Grid.Width; x++ {
+			t := m.Grid.Tiles[y][x]
+			switch {
+			case spawnChar[[2]int{x, y}] != 0:
+				b.WriteRune(spawnChar[[2]int{x, y}])
+			case t == SpawnTile:
+				// A spawn tile that is not a spawn point (e.g. a waypoint path start) is walkable path.
+				b.WriteRune(chars[PathTile])
+			default:
+				b.WriteRune(chars[t])
+			}
+		}
+		rows[y] = b.String()
+	}
+	if len(legend) == 0 {
+		legend = nil
+	}
+	return &MapDef{
+		ID:     m.ID,
+		Name:   m.Name,
+		Grid:   GridDef{Width: m.Grid.Width, Height: m.Grid.Height},
+		Base:   BaseDef{X: m.Base.X, Y: m.Base.Y, HP: m.Base.HP},
+		Tiles:  rows,
+		Legend: legend,
+
+		Pathing: m.Pathing,
+	}
+}
+
+// pickSpawnChar chooses a legend character for a spawn: the ID's first letter in upper case if it
+// is free, otherwise the first free letter or digit.
+func pickSpawnChar(id string, used map[rune]bool) rune {
+	if r, _ := utf8.DecodeRuneInString(id); r != utf8.RuneError {
+		if u := unicode.ToUpper(r); unicode.IsLetter(u) && !used[u] {
+			return u
+		}
+	}
+	for _, r := range "ABCDFGHIJKLMNOPQRTUVWXYZ0123456789abcdefghijklmnopqrstuvwxyz" {
+		if !used[r] {
+			return r
+		}
+	}
+	return '?'
+}
+
+// MarshalMap encodes a map definition as indented JSON, one tile row per line.
+func MarshalMap(def *MapDef) ([]byte, error) {
+	return json.MarshalIndent(def, "", "  ")
+}
@@ -1,13 +1,53 @@
 package mapdata
 
-// MapDef is the JSON-serializable map definition (Option B: no tile matrix).
+import "terminal-td/internal/content"
+
+// MapDef is the JSON-serializable map definition. A map is either waypoint-based (spawns and
+// straight path segments, no tile matrix) or a tile map: an ASCII tile layer read through a
+// legend, with spawns and base taken from their tiles (see tiles.go).
 type MapDef struct {
 	ID     string     `json:"id"`
 	Name   string     `json:"name"`
 	Grid   GridDef    `json:"grid"`
-	Spawns []SpawnDef `json:"spawns"`
-	Paths  []PathDef  `json:"paths"`
+	Spawns []SpawnDef `json:"spawns,omitempty"`
+	Paths  []PathDef  `json:"paths,omitempty"`
 	Base   BaseDef    `json:"base"`
+
+	Tiles  []string          `json:"tiles,omitempty"`  // one string per row; each character is a tile
+	Legend map[string]string `json:"legend,omitempty"` // character -> tile name, added to DefaultLegend
+
+	Terrain []TerrainDef `json:"terrain,omitempty"` // waypoint maps only: areas of mud, ice, high ground, ...
+
+	Pathing PathingDef `json:"pathing,omitzero"`
+}
+
+// PathingDef tunes how enemies pick their route. Terrain always counts (mud is avoided, ice
+// preferred); the zero value otherwise gives the shortest 4-way route.
+type PathingDef struct {
+	Diagonal   bool    `json:"diagonal,omitempty"`   // enemies also move diagonally, never around a blocked corner
+	Threat     float64 `json:"threat,omitempty"`     // extra cost of a tile per tower in range of it
+	Congestion float64 `json:"congestion,omitempty"` // extra cost of a tile per enemy standing on it
+}
+
+// validate rejects negative weights, with paths relative to the pathing object.
+func (p PathingDef) validate() []content.Problem {
+	var problems []content.Problem
+	if p.Threat < 0 {
+		problems = append(problems, content.Problemf(".threat", "pathing threat must not be negative, got %g", p.Threat))
+	}
+	if p.Congestion < 0 {
+		problems = append(problems, content.Problemf(".congestion", "pathing congestion must not be negative, got %g", p.Congestion))
+	}
+	return problems
+}
+
+// TerrainDef paints a rectangle of a waypoint map with a tile, e.g. mud over a stretch of path.
+type TerrainDef struct {
+	Tile   string `json:"tile"` // a legend tile name such as "mud" or "high_ground"
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width,omitempty"`  // defaults to 1
+	Height int    `json:"height,omitempty"` // defaults to 1
 }
 
 type GridDef struct {
@@ -52,6 +92,9 @@ type GameMap struct {
 	Spawns []SpawnPoint
 	Paths  map[string]Path // spawn_id -> path
 	Base   BaseInfo
+
+	Pathing    PathingDef
+	SpawnChars map[string]rune // tile maps: the legend character of each spawn
 }
 
 // BaseInfo is base position and HP (runtime).
@@ -0,0 +1,131 @@
+package mapdata
+
+import (
+	"fmt"
+
+	"terminal-td/internal/content"
+)
+
+// Validate checks def the way LoadMap builds it and returns every problem, with JSON paths from
+// the file's root. Whether spawns can reach the base is left to the flow field.
+func (def *MapDef) Validate() []content.Problem {
+	problems := content.Under("$.pathing", def.Pathing.validate())
+	if len(def.Tiles) > 0 {
+		_, tileProblems := parseTiles(def)
+		return append(problems, tileProblems...)
+	}
+	return append(problems, def.validateWaypoints()...)
+}
+
+// validateWaypoints checks the grid, base, spawns, paths and terrain of a waypoint map.
+func (def *MapDef) validateWaypoints() []content.Problem {
+	var problems []content.Problem
+	add := func(path, format string, args ...any) {
+		problems = append(problems, content.Problemf(path, format, args...))
+	}
+	if def.Base.HP <= 0 {
+		add("$.base.hp", "base hp must be positive, got %d", def.Base.HP)
+	}
+	w, h := def.Grid.Width, def.Grid.Height
+	if w <= 0 || h <= 0 {
+		add("$.grid", "invalid grid size %dx%d", w, h)
+		return problems
+	}
+	inBounds := func(x, y int) bool { return x >= 0 && x < w && y >= 0 && y < h }
+
+	if !inBounds(def.Base.X, def.Base.Y) {
+		add("$.base", "base (%d,%d) out of bounds for %dx%d grid", def.Base.X, def.Base.Y, w, h)
+	}
+	if len(def.Spawns) == 0 {
+		add("$.spawns", "no spawns defined")
+	}
+	spawns := make(map[string]bool)
+	for i, s := range def.Spawns {
+		path := fmt.Sprintf("$.spawns[%d]", i)
+		switch {
+		case s.ID == "":
+			add(path+".id", "spawn with empty id")
+		case spawns[s.ID]:
+			add(path+".id", "duplicate spawn id %q", s.ID)
+		default:
+			spawns[s.ID] = true
+		}
+		if !inBounds(s.X, s.Y) {
+			add(path, "spawn %q (%d,%d) out of bounds", s.ID, s.X, s.Y)
+		}
+	}
+
+	// Path tiles other than the base where a path may end: another path's tiles (a branch that
+	// merges) are fine, anything else is a dead end.
+	pathTiles := make(map[PointDef]int)
+	for i, pd := range def.Paths {
+		for j := 0; j+1 < len(pd.Points); j++ {
+			for _, p := range segmentTiles(pd.Points[j], pd.Points[j+1]) {
+				if _, ok := pathTiles[p]; !ok {
+					pathTiles[p] = i
+				}
+			}
+		}
+	}
+	for i, pd := range def.Paths {
+		path := fmt.Sprintf("$.paths[%d]", i)
+		if pd.SpawnID == "" {
+			add(path+".spawn_id", "path with empty spawn_id")
+		} else if !spawns[pd.SpawnID] {
+			add(path+".spawn_id", "spawn %q not in spawns", pd.SpawnID)
+		}
+		if len(pd.Points) < 2 {
+			add(path+".points", "path has fewer than 2 points")
+			continue
+		}
+		for j, p := range pd.Points {
+			if !inBounds(p.X, p.Y) {
+				add(fmt.Sprintf("%s.points[%d]", path, j), "point (%d,%d) out of bounds", p.X, p.Y)
+			}
+		}
+		for j := 0; j+1 < len(pd.Points); j++ {
+			a, b := pd.Points[j], pd.Points[j+1]
+			if a.X != b.X && a.Y != b.Y {
+				add(fmt.Sprintf("%s.points[%d]", path, j+1),
+					"diagonal segment (%d,%d)-(%d,%d) is not drawn; split it into horizontal and vertical segments", a.X, a.Y, b.X, b.Y)
+			}
+		}
+		end := pd.Points[len(pd.Points)-1]
+		if end.X == def.Base.X && end.Y == def.Base.Y {
+			continue
+		}
+		if other, ok := pathTiles[end]; ok && other != i {
+			continue
+		}
+		add(fmt.Sprintf("%s.points[%d]", path, len(pd.Points)-1),
+			"path ends at (%d,%d), not at the base (%d,%d) or on another path", end.X, end.Y, def.Base.X, def.Base.Y)
+	}
+
+	for i, t := range def.Terrain {
+		path := fmt.Sprintf("$.terrain[%d]", i)
+		if _, ok := TerrainTile(t.Tile); !ok {
+			add(path+".tile", "unknown terrain tile %q", t.Tile)
+		}
+		tw, th := max(t.Width, 1), max(t.Height, 1)
+		if !inBounds(t.X, t.Y) || !inBounds(t.X+tw-1, t.Y+th-1) {
+			add(path, "area (%d,%d) %dx%d out of bounds", t.X, t.Y, tw, th)
+		}
+	}
+	return problems
+}
+
+// segmentTiles returns the tiles ApplyPathSegmentsOnly draws for a segment (none for diagonals).
+func segmentTiles(a, b PointDef) []PointDef {
+	var tiles []PointDef
+	switch {
+	case a.X == b.X:
+		for y := min(a.Y, b.Y); y <= max(a.Y, b.Y); y++ {
+			tiles = append(tiles, PointDef{X: a.X, Y: y})
+		}
+	case a.Y == b.Y:
+		for x := min(a.X, b.X); x <= max(a.X, b.X); x++ {
+			tiles = append(tiles, PointDef{X: x, Y: a.Y})
+		}
+	}
+	return tiles
+}
@@ -0,0 +1,329 @@
+// Package mods finds installed mod packs: directories or zip files in the mods directory with a
+// mod.json manifest, bundling maps, waves, enemies and towers in the content layout.
+package mods
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/semver"
+
+	"terminal-td/internal/config"
+	"terminal-td/internal/content"
+	"terminal-td/internal/updater"
+)
+
+const (
+	ModsDir      = "mods"
+	ManifestFile = "mod.json"
+)
+
+// Manifest is a mod's mod.json.
+type Manifest struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Version     string `json:"version"`      // semantic version of the mod
+	GameVersion string `json:"game_version"` // oldest game version the mod works with
+	LoadOrder   int    `json:"load_order"`   // mods load in ascending order; later mods win ID collisions
+	Description string `json:"description"`
+}
+
+// Mod is an installed mod.
+type Mod struct {
+	Manifest
+	Path     string
+	Enabled  bool
+	Problem  string              // why the mod is refused; empty when it can be loaded
+	Provides map[string][]string // content subdir -> IDs the mod defines
+
+	fsys fs.FS
+}
+
+// Usable reports whether the mod can be loaded.
+func (m *Mod) Usable() bool {
+	return m.Problem == ""
+}
+
+// Active reports whether the mod is usable and enabled.
+func (m *Mod) Active() bool {
+	return m.Usable() && m.Enabled
+}
+
+// Title is the name shown for the mod.
+func (m *Mod) Title() string {
+	if m.Name != "" {
+		return m.Name
+	}
+	if m.ID != "" {
+		return m.ID
+	}
+	return filepath.Base(m.Path)
+}
+
+// Dir returns the mods directory, creating it.
+func Dir() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	modsDir := filepath.Join(dir, ModsDir)
+	if err := os.MkdirAll(modsDir, 0755); err != nil {
+		return "", err
+	}
+	return modsDir, nil
+}
+
+// Scan reads every mod in dir and checks it against the running game version. Mods are returned
+// in load order; refused mods follow, with a Problem saying why, so they can still be listed.
+// Mods are enabled unless their ID is in disabled.
+func Scan(dir, gameVersion string, disabled []string) []*Mod {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.Printf("mods: %v", err)
+		return nil
+	}
+	off := make(map[string]bool)
+	for _, id := range disabled {
+		off[id] = true
+	}
+	var mods []*Mod
+	for _, e := range entries {
+		p := filepath.Join(dir, e.Name())
+		if !e.IsDir() && !strings.HasSuffix(e.Name(), ".zip") {
+			continue
+		}
+		m := open(p, e.IsDir())
+		if m.Problem == "" {
+			m.Problem = checkManifest(m.Manifest, gameVersion)
+		}
+		m.Enabled = !off[m.ID]
+		mods = append(mods, m)
+	}
+	sort.SliceStable(mods, func(i, j int) bool {
+		if mods[i].Usable() != mods[j].Usable() {
+			return mods[i].Usable()
+		}
+		if mods[i].LoadOrder != mods[j].LoadOrder {
+			return mods[i].LoadOrder < mods[j].LoadOrder
+		}
+		return mods[i].ID < mods[j].ID
+	})
+	seen := make(map[string]string)
+	for _, m := range mods {
+		if !m.Usable() {
+			continue
+		}
+		if other, ok := seen[m.ID]; ok {
+			m.Problem = fmt.Sprintf("another mod (%s) already uses the id %q", other, m.ID)
+			continue
+		}
+		seen[m.ID] = filepath.Base(m.Path)
+	}
+	for _, m := range mods {
+		if m.Usable() {
+			log.Printf("DEBUG: mod %q %s from %s (load order %d, enabled %v)", m.ID, m.Version, m.Path, m.LoadOrder, m.Enabled)
+		} else {
+			log.Printf("DEBUG: refusing mod %s: %s", m.Path, m.Problem)
+		}
+	}
+	return mods
+}
+
+// open reads a mod's manifest and lists what it provides. Problems are recorded on the mod.
+func open(p string, isDir bool) *Mod {
+	m := &Mod{Path: p}
+	if isDir {
+		m.fsys = os.DirFS(p)
+	} else {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			m.Problem = err.Error()
+			return m
+		}
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			m.Problem = fmt.Sprintf("not a valid zip: %v", err)
+			return m
+		}
+		m.fsys = zr
+	}
+	m.fsys = manifestRoot(m.fsys)
+
+	data, err := fs.ReadFile(m.fsys, ManifestFile)
+	if err != nil {
+		m.Problem = "no " + ManifestFile
+		return m
+	}
+	if err := json.Unmarshal(data, &m.Manifest); err != nil {
+		m.Problem = fmt.Sprintf("%s: %v", ManifestFile, err)
+		return m
+	}
+	m.Provides = provides(m.fsys)
+	return m
+}
+
+// manifestRoot returns the folder holding mod.json: the root, or the single top-level folder
+// zips are often made with.
+func manifestRoot(fsys fs.FS) fs.FS {
+	if _, err := fs.Stat(fsys, ManifestFile); err == nil {
+		return fsys
+	}
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return fsys
+	}
+	sub, err := fs.Sub(fsys, entries[0].Name())
+	if err != nil {
+		return fsys
+	}
+	return sub
+}
+
+// checkManifest returns why a mod with manifest mf cannot run on gameVersion, or "".
+func checkManifest(mf Manifest, gameVersion string) string {
+	switch {
+	case mf.ID == "":
+		return ManifestFile + " has no id"
+	case !semver.IsValid(updater.NormalizeVersion(mf.Version)):
+		return fmt.Sprintf("version %q is not a semantic version (e.g. 1.0.0)", mf.Version)
+	case mf.GameVersion == "":
+		return ManifestFile + " does not say which game_version it needs"
+	case !semver.IsValid(updater.NormalizeVersion(mf.GameVersion)):
+		return fmt.Sprintf("game_version %q is not a semantic version (e.g. 0.1.6)", mf.GameVersion)
+	case updater.IsNewer(gameVersion, mf.GameVersion):
+		return fmt.Sprintf("needs terminal-td %s or newer, this is %s", updater.NormalizeVersion(mf.GameVersion), gameVersion)
+	}
+	return ""
+}
+
+// provides lists the IDs a mod defines per content subdir, without validating the files.
+func provides(fsys fs.FS) map[string][]string {
+	ids := make(map[string][]string)
+	for _, sub := range content.Subdirs {
+		entries, err := fs.ReadDir(fsys, sub)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			if sub == content.WavesDir {
+				ids[sub] = append(ids[sub], strings.TrimSuffix(e.Name(), ".json"))
+				continue
+			}
+			data, err := fs.ReadFile(fsys, path.Join(sub, e.Name()))
+			if err != nil {
+				continue
+			}
+			ids[sub] = append(ids[sub], fileIDs(sub, data)...)
+		}
+	}
+	return ids
+}
+
+// fileIDs returns the IDs defined in a map, enemy or tower file.
+func fileIDs(sub string, data []byte) []string {
+	var file struct {
+		ID      string `json:"id"`
+		Enemies []struct {
+			ID string `json:"id"`
+		} `json:"enemies"`
+		Towers []struct {
+			ID string `json:"id"`
+		} `json:"towers"`
+	}
+	if json.Unmarshal(data, &file) != nil {
+		return nil
+	}
+	var ids []string
+	switch sub {
+	case content.MapsDir:
+		ids = append(ids, file.ID)
+	case content.EnemiesDir:
+		for _, e := range file.Enemies {
+			ids = append(ids, e.ID)
+		}
+	case content.TowersDir:
+		for _, t := range file.Towers {
+			ids = append(ids, t.ID)
+		}
+	}
+	return ids
+}
+
+// Sources returns the content sources of the active mods, in load order.
+func Sources(mods []*Mod) []content.Source {
+	var sources []content.Source
+	for _, m := range mods {
+		if m.Active() {
+			sources = append(sources, content.Source{Name: m.Title(), FS: m.fsys, Path: m.Path, ModID: m.ID, ModVersion: m.Version})
+		}
+	}
+	return sources
+}
+
+// Disabled returns the IDs of the installed mods that are switched off, for the config file.
+func Disabled(mods []*Mod) []string {
+	var ids []string
+	for _, m := range mods {
+		if !m.Enabled && m.ID != "" {
+			ids = append(ids, m.ID)
+		}
+	}
+	return ids
+}
+
+// Conflict is an ID defined by more than one active mod. The last mod in Mods wins.
+type Conflict struct {
+	Kind string // content subdir, e.g. "maps"
+	ID   string
+	Mods []string // mod titles in load order
+}
+
+func (c Conflict) String() string {
+	kind := map[string]string{
+		content.MapsDir:    "map",
+		content.WavesDir:   "waves for map",
+		content.EnemiesDir: "enemy",
+		content.TowersDir:  "tower",
+	}[c.Kind]
+	return fmt.Sprintf("%s %q is in %s; %s wins", kind, c.ID, strings.Join(c.Mods, ", "), c.Mods[len(c.Mods)-1])
+}
+
+// Conflicts returns the IDs defined by more than one active mod.
+func Conflicts(mods []*Mod) []Conflict {
+	var conflicts []Conflict
+	for _, sub := range content.Subdirs {
+		byID := make(map[string][]string)
+		var order []string
+		for _, m := range mods {
+			if !m.Active() {
+				continue
+			}
+			for _, id := range m.Provides[sub] {
+				if _, ok := byID[id]; !ok {
+					order = append(order, id)
+				}
+				if owners := byID[id]; len(owners) == 0 || owners[len(owners)-1] != m.Title() {
+					byID[id] = append(owners, m.Title())
+				}
+			}
+		}
+		for _, id := range order {
+			if len(byID[id]) > 1 {
+				conflicts = append(conflicts, Conflict{Kind: sub, ID: id, Mods: byID[id]})
+			}
+		}
+	}
+	return conflicts
+}
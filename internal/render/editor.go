@@ -0,0 +1,129 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+
+	"terminal-td/internal/editor"
+	mapdata "terminal-td/internal/map"
+)
+
+// MapLabel is how a map is listed: its name, and where it comes from unless it is built in.
+func MapLabel(m mapdata.MapInfo) string {
+	if m.Source != "" {
+		return m.Name + " (" + m.Source + ")"
+	}
+	return m.Name
+}
+
+// DrawEditorOpen lists what an editor can open, e.g. a new map followed by the existing maps.
+func DrawEditorOpen(screen tcell.Screen, title string, labels []string, selected int) {
+	w, h := screen.Size()
+
+	whiteStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite)
+	cyanStyle := tcell.StyleDefault.Foreground(tcell.Color(6))
+	yellowStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow)
+	greenStyle := tcell.StyleDefault.Foreground(tcell.ColorGreen)
+
+	drawText(screen, (w-len(title))/2, 2, greenStyle, title)
+
+	row := 5
+	for i, text := range labels {
+		if row >= h-3 {
+			break
+		}
+		if i == selected {
+			drawText(screen, w/2-len(text)/2-2, row, yellowStyle, "> "+text)
+		} else {
+			drawText(screen, w/2-len(text)/2, row, whiteStyle, text)
+		}
+		row++
+	}
+
+	help := "SPACE to open, ESC to return to menu"
+	drawText(screen, (w-len(help))/2, h-2, cyanStyle, help)
+}
+
+// DrawEditor draws the map being edited with the route from each spawn, the brush palette and
+// whatever keeps the map from being played.
+func DrawEditor(screen tcell.Screen, ed *editor.Editor, blinkTimer float64) {
+	w, h := screen.Size()
+
+	whiteStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite)
+	cyanStyle := tcell.StyleDefault.Foreground(tcell.Color(6))
+	yellowStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow)
+	greenStyle := tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	redStyle := tcell.StyleDefault.Foreground(tcell.ColorRed)
+
+	const headerHeight = 2
+	const footerHeight = 7
+	offsetX := max(0, (w-ed.Grid.Width)/2)
+	offsetY := headerHeight + max(0, (h-headerHeight-footerHeight-ed.Grid.Height)/2)
+
+	id := ed.ID
+	if id == "" {
+		id = "no id"
+	}
+	header := fmt.Sprintf("MAP EDITOR  %s (%s)  %dx%d  Base HP %d", ed.Name, id, ed.Grid.Width, ed.Grid.Height, ed.BaseHP)
+	if ed.Dirty {
+		header += "  [unsaved]"
+	}
+	drawText(screen, max(0, (w-len(header))/2), 0, greenStyle, header)
+
+	spawns := &mapdata.GameMap{Spawns: ed.Spawns}
+	DrawGridWithHighlights(screen, ed.Grid, spawns, offsetX, offsetY, ed.Unreachable, blinkTimer)
+	DrawPathPreview(screen, ed.Routes, offsetX, offsetY)
+	DrawCursor(screen, ed.CursorX, ed.CursorY, offsetX, offsetY)
+
+	y := h - footerHeight
+	x := 1
+	for i, t := range editor.Brushes {
+		label := fmt.Sprintf("%d %s", (i+1)%10, editor.BrushName(t))
+		style := whiteStyle
+		if i == ed.Brush {
+			label = "[" + label + "]"
+			style = yellowStyle
+		}
+		drawText(screen, x, y, style, label)
+		x += len(label) + 2
+	}
+	if s, ok := ed.SpawnAtCursor(); ok {
+		drawText(screen, x+2, y, cyanStyle, fmt.Sprintf("spawn %q", s.ID))
+	}
+	y++
+
+	if len(ed.Problems) == 0 {
+		drawText(screen, 1, y, greenStyle, "Ready: every spawn reaches the base")
+	} else {
+		text := "Problem: " + ed.Problems[0]
+		if n := len(ed.Problems) - 1; n > 0 {
+			text += fmt.Sprintf(" (+%d more)", n)
+		}
+		drawText(screen, 1, y, redStyle, text)
+	}
+	y++
+
+	if ed.Prompt != nil {
+		drawText(screen, 1, y, yellowStyle, fmt.Sprintf("%s: %s_", ed.Prompt.Label, ed.Prompt.Value))
+		y++
+		drawText(screen, 1, y, whiteStyle, ed.Status)
+		drawText(screen, 1, h-2, cyanStyle, "ENTER to apply, ESC to cancel")
+		return
+	}
+	drawText(screen, 1, y, whiteStyle, ed.Status)
+	y++
+
+	help := []string{
+		"Arrows/WASD move  SPACE paint  X erase  0-9/TAB brush  U undo  R redo",
+		"[ ] width  { } height  +/- base HP  N name spawn  M map name  I map id",
+		"CTRL+S save  P play-test  ESC back",
+	}
+	for _, line := range help {
+		if y >= h {
+			break
+		}
+		drawText(screen, 1, y, cyanStyle, line)
+		y++
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"strings"
 
 	"github.com/gdamore/tcell/v2"
+	"terminal-td/internal/content"
+	"terminal-td/internal/difficulty"
 	"terminal-td/internal/game"
 	mapdata "terminal-td/internal/map"
+	"terminal-td/internal/mods"
 )
 
 type MenuOption int
@@ -18,9 +21,53 @@ const (
 	MenuChangelog
 	MenuUpdateAvailable
 	MenuQuit
+	MenuContinue
+	MenuMods
+	MenuEditor
+	MenuWaveEditor
 )
 
-func DrawMainMenu(screen tcell.Screen, selectedOption MenuOption, updateAvailable bool, latestVersion string) {
+// MenuItems returns the main menu entries in display order. Continue is only listed when a
+// save exists and the update entry only when an update is available.
+func MenuItems(hasSave, updateAvailable bool) []MenuOption {
+	var items []MenuOption
+	if hasSave {
+		items = append(items, MenuContinue)
+	}
+	items = append(items, MenuStart, MenuEditor, MenuWaveEditor, MenuControls, MenuSettings, MenuMods, MenuChangelog)
+	if updateAvailable {
+		items = append(items, MenuUpdateAvailable)
+	}
+	return append(items, MenuQuit)
+}
+
+func menuLabel(opt MenuOption, latestVersion string) string {
+	switch opt {
+	case MenuContinue:
+		return "CONTINUE"
+	case MenuStart:
+		return "START GAME"
+	case MenuEditor:
+		return "MAP EDITOR"
+	case MenuWaveEditor:
+		return "WAVE EDITOR"
+	case MenuControls:
+		return "CONTROLS"
+	case MenuSettings:
+		return "SETTINGS"
+	case MenuMods:
+		return "MODS"
+	case MenuChangelog:
+		return "CHANGELOG"
+	case MenuUpdateAvailable:
+		return fmt.Sprintf("UPDATE AVAILABLE (%s)", latestVersion)
+	case MenuQuit:
+		return "QUIT"
+	}
+	return ""
+}
+
+func DrawMainMenu(screen tcell.Screen, items []MenuOption, selected int, latestVersion string) {
 	w, h := screen.Size()
 
 	whiteStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite)
@@ -40,89 +87,33 @@ func DrawMainMenu(screen tcell.Screen, selectedOption MenuOption, updateAvailabl
 
 	centerX := w / 2
 	row := h/2 - 2
-
-	// Start
-	startText := "START GAME"
-	style := whiteStyle
-	if selectedOption == MenuStart {
-		style = yellowStyle
-		drawText(screen, centerX-len(startText)/2-2, row, style, "> "+startText)
-	} else {
-		drawText(screen, centerX-len(startText)/2, row, style, startText)
-	}
-	row += 2
-
-	// Controls
-	controlsText := "CONTROLS"
-	style = whiteStyle
-	if selectedOption == MenuControls {
-		style = yellowStyle
-		drawText(screen, centerX-len(controlsText)/2-2, row, style, "> "+controlsText)
-	} else {
-		drawText(screen, centerX-len(controlsText)/2, row, style, controlsText)
+	step := 2
+	if len(items) > 6 {
+		row -= 2
 	}
-	row += 2
-
-	// Settings
-	settingsText := "SETTINGS"
-	style = whiteStyle
-	if selectedOption == MenuSettings {
-		style = yellowStyle
-		drawText(screen, centerX-len(settingsText)/2-2, row, style, "> "+settingsText)
-	} else {
-		drawText(screen, centerX-len(settingsText)/2, row, style, settingsText)
-	}
-	row += 2
-
-	// Changelog
-	changelogText := "CHANGELOG"
-	style = whiteStyle
-	if selectedOption == MenuChangelog {
-		style = yellowStyle
-		drawText(screen, centerX-len(changelogText)/2-2, row, style, "> "+changelogText)
-	} else {
-		drawText(screen, centerX-len(changelogText)/2, row, style, changelogText)
+	if len(items) > 7 {
+		step = 1
 	}
-	row += 2
 
-	// Update available (only if update available)
-	if updateAvailable {
-		updateText := fmt.Sprintf("UPDATE AVAILABLE (%s)", latestVersion)
-		style = whiteStyle
-		if selectedOption == MenuUpdateAvailable {
-			style = yellowStyle
-			drawText(screen, centerX-len(updateText)/2-2, row, style, "> "+updateText)
+	for i, opt := range items {
+		text := menuLabel(opt, latestVersion)
+		if i == selected {
+			drawText(screen, centerX-len(text)/2-2, row, yellowStyle, "> "+text)
 		} else {
-			drawText(screen, centerX-len(updateText)/2, row, style, updateText)
+			drawText(screen, centerX-len(text)/2, row, whiteStyle, text)
 		}
-		row += 2
+		row += step
 	}
-
-	// Quit
-	quitText := "QUIT"
-	style = whiteStyle
-	if selectedOption == MenuQuit {
-		style = yellowStyle
-		drawText(screen, centerX-len(quitText)/2-2, row, style, "> "+quitText)
-	} else {
-		drawText(screen, centerX-len(quitText)/2, row, style, quitText)
+	if step == 1 {
+		row++
 	}
 
-	row += 2
-
 	// Instructions
 	instructions := "Use ARROW KEYS or W/S to navigate, SPACE to select"
 	instX := (w - len(instructions)) / 2
 	drawText(screen, instX, row, cyanStyle, instructions)
 }
 
-func MaxMenuOption(updateAvailable bool) MenuOption {
-	if updateAvailable {
-		return MenuQuit
-	}
-	return MenuChangelog + 1 // Quit is at index 4 when update row is hidden
-}
-
 func DrawSettings(screen tcell.Screen, checkForUpdates bool) {
 	w, h := screen.Size()
 
@@ -147,6 +138,97 @@ func DrawSettings(screen tcell.Screen, checkForUpdates bool) {
 	drawText(screen, (w-len(helpText))/2, h/2+2, cyanStyle, helpText)
 }
 
+// DrawMods lists installed mods with their enabled state, why refused mods cannot load, and
+// the IDs that more than one enabled mod defines.
+func DrawMods(screen tcell.Screen, installed []*mods.Mod, conflicts []mods.Conflict, selected int, modsDir string) {
+	w, h := screen.Size()
+
+	whiteStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite)
+	yellowStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow)
+	greenStyle := tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	cyanStyle := tcell.StyleDefault.Foreground(tcell.Color(6))
+	redStyle := tcell.StyleDefault.Foreground(tcell.ColorRed)
+	grayStyle := tcell.StyleDefault.Foreground(tcell.ColorGray)
+
+	title := "MODS"
+	drawText(screen, (w-len(title))/2, 2, greenStyle, title)
+	dirText := "Mod folders and .zip files go in " + modsDir
+	drawText(screen, max(0, (w-len(dirText))/2), 3, grayStyle, dirText)
+
+	x := max(2, w/2-30)
+	y := 5
+	if len(installed) == 0 {
+		drawText(screen, x, y, whiteStyle, "No mods installed.")
+		y += 2
+	}
+	for i, m := range installed {
+		if y >= h-8 {
+			break
+		}
+		box := "[ ]"
+		style := whiteStyle
+		switch {
+		case !m.Usable():
+			box = "[!]"
+			style = redStyle
+		case m.Enabled:
+			box = "[x]"
+		default:
+			style = grayStyle
+		}
+		prefix := "  "
+		if i == selected {
+			prefix = "> "
+			if m.Usable() {
+				style = yellowStyle
+			}
+		}
+		line := fmt.Sprintf("%s%s %s", prefix, box, m.Title())
+		if m.Version != "" {
+			line += " " + m.Version
+		}
+		drawText(screen, x, y, style, line)
+		y++
+		if !m.Usable() {
+			drawText(screen, x+8, y, redStyle, "refused: "+m.Problem)
+			y++
+		} else if i == selected {
+			var parts []string
+			for _, sub := range content.Subdirs {
+				if n := len(m.Provides[sub]); n > 0 {
+					parts = append(parts, fmt.Sprintf("%d %s", n, sub))
+				}
+			}
+			detail := fmt.Sprintf("load order %d", m.LoadOrder)
+			if len(parts) > 0 {
+				detail += ", " + strings.Join(parts, ", ")
+			}
+			drawText(screen, x+8, y, cyanStyle, detail)
+			y++
+			if m.Description != "" {
+				drawText(screen, x+8, y, whiteStyle, m.Description)
+				y++
+			}
+		}
+	}
+
+	if len(conflicts) > 0 && y < h-5 {
+		y++
+		drawText(screen, x, y, yellowStyle, "CONFLICTS (later load order wins):")
+		y++
+		for _, c := range conflicts {
+			if y >= h-3 {
+				break
+			}
+			drawText(screen, x+2, y, whiteStyle, c.String())
+			y++
+		}
+	}
+
+	help := "SPACE to enable/disable, ESC to apply and return"
+	drawText(screen, (w-len(help))/2, h-2, cyanStyle, help)
+}
+
 func DrawChangelog(screen tcell.Screen, content string) {
 	w, h := screen.Size()
 
@@ -247,26 +329,31 @@ func DrawControls(screen tcell.Screen) {
 	controls := []string{
 		"MOVEMENT:",
 		"  Arrow Keys or WASD - Move cursor",
+		"  Hover an enemy - Inspect it in the bottom HUD",
 		"",
 		"BUILDING:",
 		"  B - Toggle build mode",
 		"  SPACE/ENTER - Place tower / Select tower",
+		"  TAB / SHIFT+TAB - Cycle tower type (build mode)",
 		"  ESC - Cancel build mode / Deselect",
+		"  4/5 - Upgrade selected tower (5 picks the second branch)",
+		"  6/7 - Cycle targeting / Toggle sticky targeting",
 		"",
 		"GAMEPLAY:",
 		"  P - Pause / Unpause",
+		"  N - Call next wave early (bonus)",
 		"  +/- - Increase / Decrease game speed",
 		"  R - Restart (when game over)",
 		"",
 		"QUIT:",
-		"  ESC - Quit game",
+		"  ESC - Quit game (run is saved; Continue resumes it)",
 		"",
 		"Press ESC to return to menu",
 	}
 
 	y := h/2 - 8
-	for i, line := range controls {
-		if i == 0 || i == 3 || i == 8 || i == 13 {
+	for _, line := range controls {
+		if strings.HasSuffix(line, ":") {
 			// Section headers
 			drawText(screen, w/2-len(line)/2, y, yellowStyle, line)
 		} else if line == "" {
@@ -307,7 +394,7 @@ func DrawQuitConfirm(screen tcell.Screen, selectedYes bool) {
 }
 
 // DrawMapSelection shows available maps for selection.
-func DrawMapSelection(screen tcell.Screen, maps []mapdata.MapInfo, selectedIndex int) {
+func DrawMapSelection(screen tcell.Screen, maps []mapdata.MapInfo, selectedIndex int, endless bool, preset *difficulty.Preset) {
 	w, h := screen.Size()
 
 	whiteStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite)
@@ -323,10 +410,10 @@ func DrawMapSelection(screen tcell.Screen, maps []mapdata.MapInfo, selectedIndex
 	row := h/2 - 4
 
 	for i, m := range maps {
-		if row >= h-4 {
+		if row >= h-8 {
 			break
 		}
-		text := m.Name
+		text := MapLabel(m)
 		style := whiteStyle
 		if i == selectedIndex {
 			style = yellowStyle
@@ -337,6 +424,24 @@ func DrawMapSelection(screen tcell.Screen, maps []mapdata.MapInfo, selectedIndex
 		row += 2
 	}
 
+	if preset != nil {
+		presetText := fmt.Sprintf("Difficulty: < %s >  (LEFT/RIGHT to change)", preset.Name)
+		drawText(screen, (w-len(presetText))/2, h-7, yellowStyle, presetText)
+		drawText(screen, (w-len(preset.Description))/2, h-6, whiteStyle, preset.Description)
+		statsText := fmt.Sprintf("Enemy HP x%.2f  Speed x%.2f  Count x%.2f  Interval x%.2f  Rewards x%.2f  Base HP x%.2f  Money %d",
+			preset.HPMultiplier, preset.SpeedMultiplier, preset.CountMultiplier, preset.IntervalMultiplier,
+			preset.RewardMultiplier, preset.BaseHPMultiplier, preset.StartingMoney)
+		drawText(screen, max(0, (w-len(statsText))/2), h-5, cyanStyle, statsText)
+	}
+
+	modeText := "Mode: Standard (E to toggle endless)"
+	modeStyle := whiteStyle
+	if endless {
+		modeText = "Mode: Endless (E to toggle standard)"
+		modeStyle = yellowStyle
+	}
+	drawText(screen, (w-len(modeText))/2, h-4, modeStyle, modeText)
+
 	instructions := "Use ARROW KEYS or W/S to navigate, SPACE to select, ESC to cancel"
 	instX := (w - len(instructions)) / 2
 	drawText(screen, instX, h-2, cyanStyle, instructions)
@@ -7,10 +7,13 @@ import (
 
 	"github.com/gdamore/tcell/v2"
 
+	"terminal-td/internal/damage"
+	"terminal-td/internal/effects"
 	"terminal-td/internal/entities"
 	"terminal-td/internal/flow"
 	"terminal-td/internal/game"
 	mapdata "terminal-td/internal/map"
+	"terminal-td/internal/towers"
 )
 
 func DrawGrid(screen tcell.Screen, grid *mapdata.Grid, offsetX, offsetY int) {
@@ -21,6 +24,12 @@ func DrawGridWithHighlights(screen tcell.Screen, grid *mapdata.Grid, mapData *ma
 	defaultStyle := tcell.StyleDefault
 	redStyle := tcell.StyleDefault.Foreground(tcell.ColorRed)
 	blinkRedStyle := tcell.StyleDefault.Foreground(tcell.ColorRed).Bold(true)
+	rockStyle := tcell.StyleDefault.Foreground(tcell.ColorGray)
+	waterStyle := tcell.StyleDefault.Foreground(tcell.ColorBlue)
+	buildStyle := tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	mudStyle := tcell.StyleDefault.Foreground(tcell.ColorOlive)
+	iceStyle := tcell.StyleDefault.Foreground(tcell.ColorAqua)
+	highGroundStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite).Bold(true)
 
 	shouldBlink := int(blinkTimer*4)%2 == 0
 
@@ -57,6 +66,24 @@ func DrawGridWithHighlights(screen tcell.Screen, grid *mapdata.Grid, mapData *ma
 				}
 			case mapdata.BaseTile:
 				ch = 'E'
+			case mapdata.RockTile:
+				ch = '#'
+				style = rockStyle
+			case mapdata.WaterTile:
+				ch = '~'
+				style = waterStyle
+			case mapdata.BuildTile:
+				ch = '+'
+				style = buildStyle
+			case mapdata.MudTile:
+				ch = ','
+				style = mudStyle
+			case mapdata.IceTile:
+				ch = ':'
+				style = iceStyle
+			case mapdata.HighGroundTile:
+				ch = '^'
+				style = highGroundStyle
 			}
 
 			screen.SetContent(offsetX+x, offsetY+y, ch, nil, style)
@@ -65,14 +92,52 @@ func DrawGridWithHighlights(screen tcell.Screen, grid *mapdata.Grid, mapData *ma
 }
 
 func DrawEnemies(screen tcell.Screen, enemies []*entities.Enemy, offsetX, offsetY int) {
-	style := tcell.StyleDefault.Foreground(tcell.ColorRed)
-
 	for _, e := range enemies {
 		x := offsetX + int(e.X)
 		y := offsetY + int(e.Y)
+		style := enemyStyle(e)
+
+		// Large enemies cover their whole size×size footprint.
+		for dy := 0; dy < max(e.Size, 1); dy++ {
+			for dx := 0; dx < max(e.Size, 1); dx++ {
+				screen.SetContent(x+dx, y+dy, e.Symbol, nil, style)
+			}
+		}
+	}
+}
+
+// enemyFadeColor is what an enemy's color fades toward as its HP approaches zero.
+var enemyFadeColor = [3]int32{0x40, 0x40, 0x40}
+
+// enemyStyle colors an enemy by type, faded by lost HP, with its most important status effect as background.
+func enemyStyle(e *entities.Enemy) tcell.Style {
+	frac := 1.0
+	if e.MaxHP > 0 {
+		frac = math.Max(0, math.Min(1, e.HP/e.MaxHP))
+	}
+	r, g, b := tcell.PaletteColor(e.Color).RGB()
+	lerp := func(full, faded int32) int32 {
+		return faded + int32(float64(full-faded)*frac)
+	}
+	fg := tcell.NewRGBColor(lerp(r, enemyFadeColor[0]), lerp(g, enemyFadeColor[1]), lerp(b, enemyFadeColor[2]))
+	return tcell.StyleDefault.Foreground(fg).Background(enemyEffectColor(e)).Bold(e.Boss)
+}
 
-		screen.SetContent(x, y, 'M', nil, style)
+// enemyEffectColor returns a background tint for the enemy's most important active status effect.
+func enemyEffectColor(e *entities.Enemy) tcell.Color {
+	switch {
+	case e.Effects.Has(effects.Stun):
+		return tcell.NewHexColor(0x606060)
+	case e.Effects.Has(effects.Burn):
+		return tcell.NewHexColor(0x5f2f00)
+	case e.Effects.Has(effects.Poison):
+		return tcell.NewHexColor(0x1f4f1f)
+	case e.Effects.Has(effects.Slow):
+		return tcell.NewHexColor(0x1f3f5f)
+	case e.Effects.Has(effects.ArmorShred):
+		return tcell.NewHexColor(0x3f1f4f)
 	}
+	return tcell.ColorDefault
 }
 
 // DrawPathPreview draws traced paths from spawns to base (dim overlay). Call during pre-wave.
@@ -99,6 +164,9 @@ func DrawUI(screen tcell.Screen, g *game.Game) {
 	whiteStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite)
 
 	waveText := fmt.Sprintf("Wave: %d/%d", g.GetCurrentWave(), g.GetTotalWaves())
+	if g.Options.Endless {
+		waveText = fmt.Sprintf("Wave: %d (endless)", g.GetCurrentWave())
+	}
 	enemyText := fmt.Sprintf("Enemies: %d", g.GetEnemiesAlive())
 	hpText := fmt.Sprintf("Base HP: %d", g.Base.HP)
 
@@ -120,12 +188,35 @@ func DrawUI(screen tcell.Screen, g *game.Game) {
 	drawTextRight(screen, rightEdgeX, 1, whiteStyle, scoreText)
 	drawTextRight(screen, rightEdgeX, 2, whiteStyle, runTimeText)
 
+	drawBossBar(screen, g, w)
+
 	rightRow := 3
 
+	if g.Replaying() {
+		tick, final, result := g.ReplayProgress()
+		replayText := fmt.Sprintf("REPLAY %d/%d", tick, final)
+		if result != game.ReplayPending {
+			replayText += " (" + result + ")"
+		}
+		drawTextRight(screen, rightEdgeX, rightRow, tcell.StyleDefault.Foreground(tcell.ColorFuchsia), replayText)
+	}
+
 	if g.Manager.State == game.StatePreWave {
 		nextWaveTimeText := fmt.Sprintf("Next Wave In: %s", FormatTime(g.Manager.InterWaveTimer))
 		drawTextRight(screen, rightEdgeX, rightRow+1, whiteStyle, nextWaveTimeText)
 	}
+	if !g.Replaying() && g.CanCallNextWave() {
+		cyanStyle := tcell.StyleDefault.Foreground(tcell.Color(6))
+		callText := "N: call next wave now"
+		if bonus := g.EarlyCallBonus(); bonus > 0 {
+			callText += fmt.Sprintf(" (+%d)", bonus)
+		}
+		if g.Manager.State == game.StatePreWave {
+			drawTextRight(screen, rightEdgeX, rightRow+2, cyanStyle, callText)
+		} else {
+			drawTextRight(screen, rightEdgeX, rightRow+1, cyanStyle, callText)
+		}
+	}
 
 	var stateText string
 	var stateStyle tcell.Style
@@ -154,6 +245,54 @@ func DrawUI(screen tcell.Screen, g *game.Game) {
 	}
 }
 
+// drawBossBar draws the active boss's name, phase and HP bar centered across the top rows.
+func drawBossBar(screen tcell.Screen, g *game.Game, w int) {
+	boss := g.ActiveBoss()
+	if boss == nil {
+		return
+	}
+
+	name := boss.EnemyTypeID
+	if g.EnemyDB != nil {
+		if def := g.EnemyDB.Get(boss.EnemyTypeID); def != nil && def.Name != "" {
+			name = def.Name
+		}
+	}
+	title := "BOSS: " + strings.ToUpper(name)
+	if phase := boss.PhaseName(); phase != "" {
+		title += " - " + phase
+	}
+
+	barWidth := min(40, w-60)
+	if barWidth < 10 {
+		return
+	}
+	frac := math.Max(0, math.Min(1, boss.HP/boss.MaxHP))
+	filled := int(math.Ceil(frac * float64(barWidth)))
+	hpText := fmt.Sprintf(" %.0f/%.0f", boss.HP, boss.MaxHP)
+	if boss.Shield > 0 {
+		hpText += fmt.Sprintf(" +%.0f", boss.Shield)
+	}
+
+	x := (w - barWidth - 2) / 2
+	titleStyle := tcell.StyleDefault.Foreground(tcell.ColorRed).Bold(true)
+	drawText(screen, (w-len(title))/2, 0, titleStyle, title)
+
+	bracketStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite)
+	fillStyle := tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorDarkRed)
+	emptyStyle := tcell.StyleDefault.Foreground(tcell.Color(8))
+	screen.SetContent(x, 1, '[', nil, bracketStyle)
+	for i := range barWidth {
+		if i < filled {
+			screen.SetContent(x+1+i, 1, '█', nil, fillStyle)
+		} else {
+			screen.SetContent(x+1+i, 1, '░', nil, emptyStyle)
+		}
+	}
+	screen.SetContent(x+1+barWidth, 1, ']', nil, bracketStyle)
+	drawText(screen, x+2+barWidth, 1, bracketStyle, hpText)
+}
+
 func DrawCursor(screen tcell.Screen, cursorX, cursorY, offsetX, offsetY int) {
 	style := tcell.StyleDefault.Foreground(tcell.ColorYellow).Bold(true)
 	screen.SetContent(offsetX+cursorX, offsetY+cursorY, '+', nil, style)
@@ -199,42 +338,59 @@ func DrawBottomHUD(screen tcell.Screen, g *game.Game) {
 
 	switch g.Manager.Mode {
 	case game.ModeBuild:
-		templates := game.GetTowerTemplates()
-		template := templates[entities.TowerBasic]
+		def := g.BuildTowerDef()
+		if def == nil {
+			drawText(screen, 0, hudStartY+1, redStyle, "No tower types loaded")
+			break
+		}
 
-		canAfford := g.Money >= template.Cost
+		canAfford := g.Money >= def.Cost
 		costStyle := whiteStyle
 
 		if !canAfford {
 			costStyle = redStyle
 		}
 
-		buildText := fmt.Sprintf("Build: [%c] %s - Cost: %d", template.Symbol, template.Name, template.Cost)
+		buildText := fmt.Sprintf("Build: [%c] %s - Cost: %d", def.Rune(), def.Name, def.Cost)
+		kind, _ := towers.ParseProjectileKind(def.Projectile.Kind)
+		damageType, _ := damage.Parse(def.DamageType)
+		statsText := fmt.Sprintf("DMG: %.1f %s | Rate: %.2f/s | Range: %.1f | Shot: %s", def.Damage, damageType, def.FireRate, def.Range, kind)
 		moneyText := fmt.Sprintf("Money: %d", g.Money)
-		helpText := "Press SPACE/ENTER to build, ESC/B to cancel"
+		helpText := fmt.Sprintf("SPACE/ENTER build, TAB next type (%d/%d), ESC/B cancel", g.Manager.BuildIndex+1, len(g.TowerDB.Order))
 
 		drawText(screen, 0, hudStartY+1, costStyle, buildText)
+		drawText(screen, len(buildText)+3, hudStartY+1, whiteStyle, statsText)
 		drawText(screen, 0, hudStartY+2, whiteStyle, moneyText)
+		drawText(screen, len(moneyText)+3, hudStartY+2, cyanStyle, def.Description)
 		drawText(screen, 0, hudStartY+3, cyanStyle, helpText)
 
 		if g.CanPlaceTower(g.CursorX, g.CursorY) {
-			drawText(screen, 0, hudStartY+4, greenStyle, "✓ Valid placement")
+			placeText := "✓ Valid placement"
+			if bonus := g.RangeBonusAt(g.CursorX, g.CursorY); bonus > 0 {
+				placeText += fmt.Sprintf(" (high ground: +%.1f range)", bonus)
+			}
+			drawText(screen, 0, hudStartY+4, greenStyle, placeText)
 		} else {
-			drawText(screen, 0, hudStartY+4, redStyle, "✗ Invalid placement (on path or existing tower)")
+			drawText(screen, 0, hudStartY+4, redStyle, "✗ Invalid placement (path, blocked tile or existing tower)")
 		}
 
 	case game.ModeSelect:
 		tower := g.GetTowerAt(g.Manager.SelectedTowerX, g.Manager.SelectedTowerY)
 		if tower != nil {
-			templates := game.GetTowerTemplates()
-			template := templates[tower.Type]
+			name := tower.TypeID
+			def := g.TowerDB.Get(tower.TypeID)
+			if def != nil {
+				name = def.Name
+			}
 			dps := tower.Damage * tower.FireRate
 			linkable := g.GetLinkableTowers(g.Manager.SelectedTowerX, g.Manager.SelectedTowerY)
 			greyStyle := tcell.StyleDefault.Foreground(tcell.Color(8)).Dim(true)
 
+			sellText := fmt.Sprintf("3. Sell tower (+%d)  0. Deselect", g.SellRefund(tower.X, tower.Y))
 			wallsForTower := g.GetWallsForTower(g.Manager.SelectedTowerX, g.Manager.SelectedTowerY)
-			drawText(screen, 0, hudStartY+1, whiteStyle, fmt.Sprintf("Tower: [%c] %s", tower.Symbol, template.Name))
-			drawText(screen, 0, hudStartY+2, whiteStyle, fmt.Sprintf("DPS: %.1f | Range: %.1f", dps, tower.Range))
+			drawText(screen, 0, hudStartY+1, whiteStyle, fmt.Sprintf("Tower: [%c] %s | Target: %s (%s)", tower.Symbol, name, tower.Targeting, tower.Retarget))
+			drawText(screen, 0, hudStartY+2, whiteStyle, fmt.Sprintf("DPS: %.1f %s | Range: %.1f | Money: %d", dps, tower.DamageType, tower.Range, g.Money))
+			drawUpgradePanel(screen, g, tower, def, w/2, hudStartY+1)
 			if g.Manager.SelectingWallTarget {
 				drawText(screen, 0, hudStartY+3, cyanStyle, "Select a green tower to link (SPACE/ENTER), 0 cancel")
 			} else if g.Manager.SelectingWallRemoveTarget {
@@ -247,10 +403,10 @@ func DrawBottomHUD(screen tcell.Screen, g *game.Game) {
 				}
 				if len(wallsForTower) == 0 {
 					drawText(screen, 0, hudStartY+4, greyStyle, "2. Remove wall (none)  ")
-					drawText(screen, 24, hudStartY+4, cyanStyle, "3. Sell tower  0. Deselect")
+					drawText(screen, 24, hudStartY+4, cyanStyle, sellText)
 				} else {
 					drawText(screen, 0, hudStartY+4, greenStyle, "2. Remove wall  ")
-					drawText(screen, 16, hudStartY+4, cyanStyle, "3. Sell tower  0. Deselect")
+					drawText(screen, 16, hudStartY+4, cyanStyle, sellText)
 				}
 			}
 		}
@@ -259,6 +415,92 @@ func DrawBottomHUD(screen tcell.Screen, g *game.Game) {
 		helpText := "Press SPACE on empty tile to build, on tower to select"
 		drawText(screen, 0, hudStartY+1, whiteStyle, moneyText)
 		drawText(screen, 0, hudStartY+2, cyanStyle, helpText)
+		if e := g.EnemyAt(g.CursorX, g.CursorY); e != nil {
+			drawEnemyInspector(screen, g, e, 0, hudStartY+3)
+		}
+	}
+}
+
+// drawEnemyInspector draws the hovered enemy's type, HP, speed, distance to base and effects on two rows at (x, y).
+func drawEnemyInspector(screen tcell.Screen, g *game.Game, e *entities.Enemy, x, y int) {
+	whiteStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite)
+	cyanStyle := tcell.StyleDefault.Foreground(tcell.Color(6))
+
+	name := e.EnemyTypeID
+	if g.EnemyDB != nil {
+		if def := g.EnemyDB.Get(e.EnemyTypeID); def != nil && def.Name != "" {
+			name = def.Name
+		}
+	}
+	hpText := fmt.Sprintf("%.0f/%.0f", e.HP, e.MaxHP)
+	if e.Shield > 0 {
+		hpText += fmt.Sprintf(" (+%.0f shield)", e.Shield)
+	}
+	distText := "?"
+	if dist := g.RemainingDistance(e); dist < flow.Inf {
+		distText = fmt.Sprintf("%.1f", dist)
+	}
+	drawText(screen, x, y, whiteStyle, "Enemy: ")
+	drawText(screen, x+7, y, enemyStyle(e).Background(tcell.ColorDefault), fmt.Sprintf("[%c]", e.Symbol))
+	drawText(screen, x+10, y, whiteStyle, fmt.Sprintf(" %s | HP: %s | Speed: %.1f | To base: %s", name, hpText, e.CurrentSpeed(), distText))
+
+	var parts []string
+	for _, st := range e.Effects {
+		part := fmt.Sprintf("%s %.1fs", st.Kind, st.Remaining)
+		if st.Stacks > 1 {
+			part = fmt.Sprintf("%s x%d %.1fs", st.Kind, st.Stacks, st.Remaining)
+		}
+		parts = append(parts, part)
+	}
+	effectsText := "Effects: none"
+	if len(parts) > 0 {
+		effectsText = "Effects: " + strings.Join(parts, ", ")
+	}
+	if phase := e.PhaseName(); phase != "" {
+		effectsText += " | Phase: " + phase
+	}
+	drawText(screen, x, y+1, cyanStyle, effectsText)
+}
+
+// drawUpgradePanel draws the tower level, purchasable upgrades (keys 4, 5) and targeting keys starting at (x, y).
+func drawUpgradePanel(screen tcell.Screen, g *game.Game, tower *entities.Tower, def *towers.TowerDef, x, y int) {
+	whiteStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite)
+	redStyle := tcell.StyleDefault.Foreground(tcell.ColorRed)
+	greenStyle := tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	greyStyle := tcell.StyleDefault.Foreground(tcell.Color(8)).Dim(true)
+
+	levelText := fmt.Sprintf("Level %d", tower.Level)
+	if def != nil {
+		if b := def.Branch(tower.Branch); b != nil {
+			levelText += " - " + b.Name
+		}
+	}
+	drawText(screen, x, y, whiteStyle, levelText)
+
+	cyanStyle := tcell.StyleDefault.Foreground(tcell.Color(6))
+	drawText(screen, x, y+3, cyanStyle, "6. Cycle targeting  7. Sticky/each shot")
+
+	options := g.TowerUpgrades(tower.X, tower.Y)
+	if len(options) == 0 {
+		drawText(screen, x, y+1, greyStyle, "Max level")
+		return
+	}
+	for i, opt := range options {
+		if i > 1 {
+			break
+		}
+		label := opt.Def.Name
+		if opt.BranchID != "" && tower.Branch == "" {
+			if b := def.Branch(opt.BranchID); b != nil {
+				label = b.Name + ": " + label
+			}
+		}
+		text := fmt.Sprintf("%d. %s (%s) - %d", i+4, label, opt.Def.Summary(), opt.Cost)
+		style := greenStyle
+		if g.Money < opt.Cost {
+			style = redStyle
+		}
+		drawText(screen, x, y+1+i, style, text)
 	}
 }
 
@@ -282,13 +524,43 @@ func DrawRange(screen tcell.Screen, centerX, centerY int, rangeVal float64, offs
 }
 
 func DrawProjectiles(screen tcell.Screen, projectiles []*entities.Projectile, offsetX, offsetY int) {
-	style := tcell.StyleDefault.Foreground(tcell.ColorYellow)
+	singleStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow)
+	splashStyle := tcell.StyleDefault.Foreground(tcell.ColorRed).Bold(true)
+	pierceStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite).Bold(true)
+	chainStyle := tcell.StyleDefault.Foreground(tcell.Color(6)).Bold(true)
 
 	for _, proj := range projectiles {
 		x := offsetX + int(proj.X)
 		y := offsetY + int(proj.Y)
 
-		screen.SetContent(x, y, '*', nil, style)
+		switch proj.Kind {
+		case towers.ProjectileSplash:
+			screen.SetContent(x, y, 'o', nil, splashStyle)
+		case towers.ProjectilePierce:
+			screen.SetContent(x, y, pierceGlyph(proj.DirX, proj.DirY), nil, pierceStyle)
+		case towers.ProjectileChain:
+			screen.SetContent(x, y, '~', nil, chainStyle)
+		default:
+			screen.SetContent(x, y, '*', nil, singleStyle)
+		}
+	}
+}
+
+// pierceGlyph picks a line character matching the bolt's direction.
+func pierceGlyph(dx, dy float64) rune {
+	angle := math.Atan2(dy, dx) * 180 / math.Pi
+	if angle < 0 {
+		angle += 180
+	}
+	switch {
+	case angle < 22.5 || angle >= 157.5:
+		return '-'
+	case angle < 67.5:
+		return '\\'
+	case angle < 112.5:
+		return '|'
+	default:
+		return '/'
 	}
 }
 
@@ -0,0 +1,176 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+
+	"terminal-td/internal/editor"
+)
+
+// DrawWaveEditor draws the wave list with per-wave totals, the groups of the selected wave and a
+// timeline of when each group sends its enemies.
+func DrawWaveEditor(screen tcell.Screen, we *editor.WaveEditor) {
+	w, h := screen.Size()
+
+	whiteStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite)
+	cyanStyle := tcell.StyleDefault.Foreground(tcell.Color(6))
+	yellowStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow)
+	greenStyle := tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	grayStyle := tcell.StyleDefault.Foreground(tcell.ColorGray)
+	selectedStyle := tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorYellow)
+
+	header := fmt.Sprintf("WAVE EDITOR  %s  %d waves", we.MapID, len(we.Waves))
+	if we.Dirty {
+		header += "  [unsaved]"
+	}
+	drawText(screen, max(0, (w-len(header))/2), 0, greenStyle, header)
+
+	const listTop = 2
+	const listRows = 10
+	first := max(0, we.Wave-listRows+1)
+	for i := first; i < len(we.Waves) && i < first+listRows; i++ {
+		t := we.Totals(i)
+		line := fmt.Sprintf("Wave %-3d %3d foes  HP %-6.0f $%d", we.Waves[i].Wave, t.Enemies, t.HP, t.Reward)
+		style := whiteStyle
+		if i == we.Wave {
+			line = "> " + line
+			style = yellowStyle
+		} else {
+			line = "  " + line
+		}
+		drawText(screen, 1, listTop+i-first, style, line)
+	}
+
+	tableX := 38
+	widths := []int{10, 10, 6, 9, 6}
+	x := tableX
+	for i, name := range editor.FieldNames {
+		drawText(screen, x, listTop, cyanStyle, name)
+		x += widths[i]
+	}
+	wave := we.Selected()
+	if wave != nil {
+		firstGroup := max(0, we.Group-listRows+2)
+		for gi := firstGroup; gi < len(wave.Groups) && gi < firstGroup+listRows-1; gi++ {
+			g := wave.Groups[gi]
+			cells := []string{
+				g.SpawnID,
+				g.EnemyType,
+				fmt.Sprintf("%d", g.Count),
+				fmt.Sprintf("%.1fs", g.Interval),
+				fmt.Sprintf("%.1fs", g.StartDelay),
+			}
+			x := tableX
+			y := listTop + 1 + gi - firstGroup
+			for fi, cell := range cells {
+				style := whiteStyle
+				if gi == we.Group {
+					style = yellowStyle
+					if fi == we.Field {
+						style = selectedStyle
+					}
+				}
+				if len(cell) > widths[fi]-1 {
+					cell = cell[:widths[fi]-1]
+				}
+				drawText(screen, x, y, style, cell)
+				x += widths[fi]
+			}
+		}
+	}
+
+	y := listTop + listRows + 1
+	if wave != nil {
+		t := we.Totals(we.Wave)
+		summary := fmt.Sprintf("Wave %d: %d enemies, total HP %.0f, reward $%d, last spawn at %.1fs",
+			wave.Wave, t.Enemies, t.HP, t.Reward, t.Duration)
+		drawText(screen, 1, y, greenStyle, summary)
+		y++
+		drawTimeline(screen, we, t.Duration, y, h-5, w)
+	}
+
+	drawText(screen, 1, h-4, whiteStyle, we.Status)
+	help := []string{
+		"W/S group  A/D column  [ ] wave  +/- change value  G add group  X remove group",
+		"N new wave (copy of this one)  CTRL+S save  ESC back",
+	}
+	for i, line := range help {
+		drawText(screen, 1, h-3+i, grayStyle, line)
+	}
+}
+
+// drawTimeline draws one row per group of the selected wave, marking each enemy at the moment it
+// spawns, over a time axis. Rows go from top to bottom-1; the axis is on the last row.
+func drawTimeline(screen tcell.Screen, we *editor.WaveEditor, duration float64, top, bottom, w int) {
+	whiteStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite)
+	yellowStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow)
+	grayStyle := tcell.StyleDefault.Foreground(tcell.ColorGray)
+
+	const labelWidth = 20
+	barX := labelWidth + 1
+	barWidth := w - barX - 2
+	if barWidth < 10 || bottom <= top {
+		return
+	}
+	duration = max(duration, 1)
+	col := func(t float64) int {
+		return barX + int(t/duration*float64(barWidth-1)+0.5)
+	}
+
+	wave := we.Selected()
+	rows := bottom - top - 1
+	groups := wave.Groups
+	if len(groups) > rows {
+		groups = groups[:rows-1]
+		drawText(screen, 1, top+rows-1, grayStyle, fmt.Sprintf("(+%d more groups)", len(wave.Groups)-len(groups)))
+	}
+	for gi, g := range groups {
+		y := top + gi
+		style := whiteStyle
+		if gi == we.Group {
+			style = yellowStyle
+		}
+		label := fmt.Sprintf("%s %s", g.SpawnID, g.EnemyType)
+		if len(label) > labelWidth-1 {
+			label = label[:labelWidth-1]
+		}
+		drawText(screen, 1, y, style, label)
+
+		times := editor.SpawnTimes(g)
+		if len(times) == 0 {
+			continue
+		}
+		for x := col(g.StartDelay); x <= col(times[len(times)-1]); x++ {
+			screen.SetContent(x, y, '-', nil, grayStyle)
+		}
+		glyph := '*'
+		if def := we.EnemyDB.Get(g.EnemyType); def != nil && def.Rune() != 0 {
+			glyph = def.Rune()
+		}
+		for _, t := range times {
+			screen.SetContent(col(t), y, glyph, nil, style)
+		}
+	}
+
+	// Axis with a labelled tick about every 8 columns.
+	axisY := bottom - 1
+	step := 1.0
+	for _, s := range []float64{1, 2, 5, 10, 15, 30, 60, 120} {
+		step = s
+		if duration/s*8 <= float64(barWidth) {
+			break
+		}
+	}
+	for x := barX; x < barX+barWidth; x++ {
+		screen.SetContent(x, axisY, '-', nil, grayStyle)
+	}
+	for t := 0.0; t <= duration; t += step {
+		x := col(t)
+		screen.SetContent(x, axisY, '|', nil, grayStyle)
+		label := fmt.Sprintf("%gs", t)
+		if x+len(label) < barX+barWidth {
+			drawText(screen, x+1, axisY, grayStyle, label)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package towers
+
+import (
+	"bytes"
+	"embed"
+	"log"
+
+	"terminal-td/internal/content"
+)
+
+//go:embed data/towers.json
+var defaultTowersFS embed.FS
+
+// DefaultTowers returns the built-in tower definitions merged with the tower files from mods and
+// the content directory. A file that fails to load is skipped.
+func DefaultTowers() (*TowerDatabase, error) {
+	data, err := defaultTowersFS.ReadFile("data/towers.json")
+	if err != nil {
+		return nil, err
+	}
+	db, err := LoadTowersBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range content.Files(content.TowersDir) {
+		data, err := f.Read()
+		if err != nil {
+			log.Printf("DEBUG: skipping towers %s: %v", f.Path, err)
+			continue
+		}
+		n, err := db.Merge(bytes.NewReader(data))
+		if err != nil {
+			log.Printf("DEBUG: skipping towers %s: %v", f.Path, err)
+			continue
+		}
+		log.Printf("DEBUG: merged %d %s towers from %s", n, f.Source, f.Path)
+	}
+	return db, nil
+}
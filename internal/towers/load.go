@@ -0,0 +1,170 @@
+package towers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"unicode/utf8"
+
+	"terminal-td/internal/damage"
+)
+
+const (
+	defaultProjectileSpeed  = 20.0
+	defaultUpgradeCostScale = 1.5
+)
+
+// LoadTowers reads tower definitions from r and returns a database.
+func LoadTowers(r io.Reader) (*TowerDatabase, error) {
+	db := &TowerDatabase{
+		Towers: make(map[string]TowerDef),
+	}
+	defs, err := loadTowerDefs(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, def := range defs {
+		db.Towers[def.ID] = def
+		db.Order = append(db.Order, def.ID)
+	}
+	return db, nil
+}
+
+// Merge adds the towers in r to db. A tower with the ID of an existing one replaces it and keeps
+// its build menu position; new towers are added to the end of the menu. On error db is unchanged.
+func (db *TowerDatabase) Merge(r io.Reader) (int, error) {
+	defs, err := loadTowerDefs(r)
+	if err != nil {
+		return 0, err
+	}
+	for _, def := range defs {
+		if _, ok := db.Towers[def.ID]; !ok {
+			db.Order = append(db.Order, def.ID)
+		}
+		db.Towers[def.ID] = def
+	}
+	return len(defs), nil
+}
+
+// loadTowerDefs decodes and validates the towers in r, in file order.
+func loadTowerDefs(r io.Reader) ([]TowerDef, error) {
+	var defs struct {
+		Towers []TowerDef `json:"towers"`
+	}
+	if err := json.NewDecoder(r).Decode(&defs); err != nil {
+		return nil, fmt.Errorf("tower decode: %w", err)
+	}
+	seen := make(map[string]bool)
+	var out []TowerDef
+	for _, def := range defs.Towers {
+		if def.ID == "" {
+			return nil, fmt.Errorf("tower with empty id")
+		}
+		if utf8.RuneCountInString(def.Symbol) != 1 {
+			return nil, fmt.Errorf("tower %q symbol must be a single character, got %q", def.ID, def.Symbol)
+		}
+		if def.Cost <= 0 {
+			return nil, fmt.Errorf("tower %q has invalid cost %d", def.ID, def.Cost)
+		}
+		if def.Range <= 0 {
+			return nil, fmt.Errorf("tower %q has invalid range %f", def.ID, def.Range)
+		}
+		if def.Damage < 0 {
+			return nil, fmt.Errorf("tower %q has invalid damage %f", def.ID, def.Damage)
+		}
+		if def.FireRate <= 0 {
+			return nil, fmt.Errorf("tower %q has invalid fire_rate %f", def.ID, def.FireRate)
+		}
+		if def.ProjectileSpeed < 0 {
+			return nil, fmt.Errorf("tower %q has invalid projectile_speed %f", def.ID, def.ProjectileSpeed)
+		}
+		if def.ProjectileSpeed == 0 {
+			def.ProjectileSpeed = defaultProjectileSpeed
+		}
+		if _, err := damage.Parse(def.DamageType); err != nil {
+			return nil, fmt.Errorf("tower %q: %w", def.ID, err)
+		}
+		if _, err := ParseTargetingMode(def.Targeting); err != nil {
+			return nil, fmt.Errorf("tower %q: %w", def.ID, err)
+		}
+		if _, err := ParseRetargetPolicy(def.Retarget); err != nil {
+			return nil, fmt.Errorf("tower %q: %w", def.ID, err)
+		}
+		if err := def.Projectile.validate(); err != nil {
+			return nil, fmt.Errorf("tower %q: %w", def.ID, err)
+		}
+		for i := range def.Effects {
+			if err := def.Effects[i].Validate(); err != nil {
+				return nil, fmt.Errorf("tower %q effect %d: %w", def.ID, i, err)
+			}
+		}
+		if err := validateUpgrades(&def); err != nil {
+			return nil, err
+		}
+		if seen[def.ID] {
+			return nil, fmt.Errorf("duplicate tower id %q", def.ID)
+		}
+		seen[def.ID] = true
+		out = append(out, def)
+		log.Printf("loaded tower: id=%q name=%q cost=%d range=%.1f damage=%.1f fire_rate=%.2f",
+			def.ID, def.Name, def.Cost, def.Range, def.Damage, def.FireRate)
+	}
+	return out, nil
+}
+
+// maxUpgradeBranches is how many branches the upgrade panel shows and has keys for.
+const maxUpgradeBranches = 2
+
+func validateUpgrades(def *TowerDef) error {
+	up := &def.Upgrades
+	if up.CostScale < 0 {
+		return fmt.Errorf("tower %q has invalid upgrades.cost_scale %f", def.ID, up.CostScale)
+	}
+	if up.CostScale == 0 {
+		up.CostScale = defaultUpgradeCostScale
+	}
+	for i, u := range up.Levels {
+		if err := validateUpgrade(u); err != nil {
+			return fmt.Errorf("tower %q upgrade level %d: %w", def.ID, i+1, err)
+		}
+	}
+	if len(up.Branches) == 1 {
+		return fmt.Errorf("tower %q declares a single upgrade branch; branches need at least two choices", def.ID)
+	}
+	if len(up.Branches) > maxUpgradeBranches {
+		return fmt.Errorf("tower %q declares %d upgrade branches; a tower can offer at most %d", def.ID, len(up.Branches), maxUpgradeBranches)
+	}
+	seen := make(map[string]bool)
+	for _, b := range up.Branches {
+		if b.ID == "" {
+			return fmt.Errorf("tower %q has upgrade branch with empty id", def.ID)
+		}
+		if seen[b.ID] {
+			return fmt.Errorf("tower %q has duplicate upgrade branch %q", def.ID, b.ID)
+		}
+		seen[b.ID] = true
+		if len(b.Levels) == 0 {
+			return fmt.Errorf("tower %q upgrade branch %q has no levels", def.ID, b.ID)
+		}
+		for i, u := range b.Levels {
+			if err := validateUpgrade(u); err != nil {
+				return fmt.Errorf("tower %q branch %q level %d: %w", def.ID, b.ID, i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateUpgrade(u UpgradeDef) error {
+	if u.Cost < 0 {
+		return fmt.Errorf("invalid cost %d", u.Cost)
+	}
+	return nil
+}
+
+// LoadTowersBytes parses tower JSON from bytes (for embed or tests).
+func LoadTowersBytes(data []byte) (*TowerDatabase, error) {
+	return LoadTowers(bytes.NewReader(data))
+}
@@ -0,0 +1,99 @@
+package towers
+
+import "fmt"
+
+// ProjectileKind selects how a tower's shots travel and deal damage.
+type ProjectileKind int
+
+const (
+	ProjectileSingle ProjectileKind = iota // homing, damages its target only
+	ProjectileSplash                       // homing, explodes and damages everything in Radius
+	ProjectilePierce                       // straight line, damages every enemy it crosses
+	ProjectileChain                        // homing, jumps to nearby enemies with decaying damage
+)
+
+var projectileKindNames = []string{"single", "splash", "pierce", "chain"}
+
+func (k ProjectileKind) String() string {
+	if k < 0 || int(k) >= len(projectileKindNames) {
+		return "unknown"
+	}
+	return projectileKindNames[k]
+}
+
+// ParseProjectileKind parses a JSON projectile kind; empty means ProjectileSingle.
+func ParseProjectileKind(s string) (ProjectileKind, error) {
+	if s == "" {
+		return ProjectileSingle, nil
+	}
+	for i, name := range projectileKindNames {
+		if name == s {
+			return ProjectileKind(i), nil
+		}
+	}
+	return ProjectileSingle, fmt.Errorf("unknown projectile kind %q", s)
+}
+
+// ProjectileDef configures a tower's projectile. Only the fields of the chosen kind are used.
+type ProjectileDef struct {
+	Kind string `json:"kind"`
+
+	// splash
+	Radius  float64 `json:"radius"`  // explosion radius in tiles
+	Falloff float64 `json:"falloff"` // 0 = full damage everywhere, 1 = no damage at the edge
+
+	// pierce
+	MaxDistance float64 `json:"max_distance"` // travel distance; 0 = 1.5x tower range
+	Width       float64 `json:"width"`        // hit distance from the bolt's line
+
+	// chain
+	Jumps      int     `json:"jumps"`       // extra enemies hit after the first
+	JumpRadius float64 `json:"jump_radius"` // max distance between consecutive targets
+	Decay      float64 `json:"decay"`       // damage multiplier per jump
+}
+
+const (
+	defaultPierceWidth = 0.6
+	defaultChainDecay  = 0.7
+)
+
+// validate checks kind-specific parameters and fills defaults.
+func (p *ProjectileDef) validate() error {
+	kind, err := ParseProjectileKind(p.Kind)
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case ProjectileSplash:
+		if p.Radius <= 0 {
+			return fmt.Errorf("splash projectile has invalid radius %f", p.Radius)
+		}
+		if p.Falloff < 0 || p.Falloff > 1 {
+			return fmt.Errorf("splash projectile falloff %f must be in [0,1]", p.Falloff)
+		}
+	case ProjectilePierce:
+		if p.MaxDistance < 0 {
+			return fmt.Errorf("pierce projectile has invalid max_distance %f", p.MaxDistance)
+		}
+		if p.Width < 0 {
+			return fmt.Errorf("pierce projectile has invalid width %f", p.Width)
+		}
+		if p.Width == 0 {
+			p.Width = defaultPierceWidth
+		}
+	case ProjectileChain:
+		if p.Jumps <= 0 {
+			return fmt.Errorf("chain projectile has invalid jumps %d", p.Jumps)
+		}
+		if p.JumpRadius <= 0 {
+			return fmt.Errorf("chain projectile has invalid jump_radius %f", p.JumpRadius)
+		}
+		if p.Decay < 0 || p.Decay > 1 {
+			return fmt.Errorf("chain projectile decay %f must be in [0,1]", p.Decay)
+		}
+		if p.Decay == 0 {
+			p.Decay = defaultChainDecay
+		}
+	}
+	return nil
+}
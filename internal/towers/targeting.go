@@ -0,0 +1,68 @@
+package towers
+
+import "fmt"
+
+// TargetingMode decides which enemy in range a tower shoots at.
+type TargetingMode int
+
+const (
+	TargetFirst     TargetingMode = iota // closest to the base (lowest flow distance)
+	TargetLast                           // furthest from the base
+	TargetStrongest                      // highest current HP
+	TargetWeakest                        // lowest current HP
+	TargetClosest                        // closest to the tower
+	TargetFastest                        // highest speed
+)
+
+var targetingModeNames = []string{"first", "last", "strongest", "weakest", "closest", "fastest"}
+
+func (m TargetingMode) String() string {
+	if m < 0 || int(m) >= len(targetingModeNames) {
+		return "unknown"
+	}
+	return targetingModeNames[m]
+}
+
+// Next returns the following mode (wraps around), for cycling in the HUD.
+func (m TargetingMode) Next() TargetingMode {
+	return TargetingMode((int(m) + 1) % len(targetingModeNames))
+}
+
+// ParseTargetingMode parses a JSON targeting name; empty means TargetFirst.
+func ParseTargetingMode(s string) (TargetingMode, error) {
+	if s == "" {
+		return TargetFirst, nil
+	}
+	for i, name := range targetingModeNames {
+		if name == s {
+			return TargetingMode(i), nil
+		}
+	}
+	return TargetFirst, fmt.Errorf("unknown targeting mode %q", s)
+}
+
+// RetargetPolicy decides when a tower gives up its current target.
+type RetargetPolicy int
+
+const (
+	RetargetSticky   RetargetPolicy = iota // keep target until it dies or leaves range
+	RetargetEachShot                       // re-evaluate the targeting mode before every shot
+)
+
+func (p RetargetPolicy) String() string {
+	if p == RetargetEachShot {
+		return "each_shot"
+	}
+	return "sticky"
+}
+
+// ParseRetargetPolicy parses a JSON retarget name; empty means RetargetSticky.
+func ParseRetargetPolicy(s string) (RetargetPolicy, error) {
+	switch s {
+	case "", "sticky":
+		return RetargetSticky, nil
+	case "each_shot":
+		return RetargetEachShot, nil
+	}
+	return RetargetSticky, fmt.Errorf("unknown retarget policy %q", s)
+}
@@ -0,0 +1,158 @@
+package towers
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"terminal-td/internal/effects"
+)
+
+// TowerDef is the JSON-serializable tower definition.
+type TowerDef struct {
+	ID              string  `json:"id"`
+	Name            string  `json:"name"`
+	Description     string  `json:"description"`
+	Symbol          string  `json:"symbol"`
+	Color           int     `json:"color"`
+	Cost            int     `json:"cost"`
+	Range           float64 `json:"range"`
+	Damage          float64 `json:"damage"`
+	DamageType      string  `json:"damage_type"` // physical (default), magic or true
+	FireRate        float64 `json:"fire_rate"`
+	ProjectileSpeed float64 `json:"projectile_speed"`
+	Targeting       string  `json:"targeting"` // default targeting mode (first, last, strongest, ...)
+	Retarget        string  `json:"retarget"`  // sticky or each_shot
+
+	Projectile ProjectileDef `json:"projectile"`
+	Effects    []effects.Def `json:"effects"` // applied to every enemy a projectile damages
+
+	Upgrades UpgradeTree `json:"upgrades"`
+}
+
+// UpgradeTree declares linear upgrade levels followed by mutually exclusive branches.
+type UpgradeTree struct {
+	CostScale float64         `json:"cost_scale"` // used for levels without an explicit cost
+	Levels    []UpgradeDef    `json:"levels"`
+	Branches  []UpgradeBranch `json:"branches"`
+}
+
+// UpgradeBranch is a specialization chosen after all linear levels are bought.
+type UpgradeBranch struct {
+	ID     string       `json:"id"`
+	Name   string       `json:"name"`
+	Levels []UpgradeDef `json:"levels"`
+}
+
+// UpgradeDef is one purchasable upgrade; stat fields are added to the tower.
+type UpgradeDef struct {
+	Name     string  `json:"name"`
+	Cost     int     `json:"cost"`
+	Range    float64 `json:"range"`
+	Damage   float64 `json:"damage"`
+	FireRate float64 `json:"fire_rate"`
+}
+
+// Summary returns a short description of the stat changes (e.g. "+4 DMG +1.0 RNG").
+func (u *UpgradeDef) Summary() string {
+	var parts []string
+	if u.Damage != 0 {
+		parts = append(parts, fmt.Sprintf("%+g DMG", u.Damage))
+	}
+	if u.FireRate != 0 {
+		parts = append(parts, fmt.Sprintf("%+g RATE", u.FireRate))
+	}
+	if u.Range != 0 {
+		parts = append(parts, fmt.Sprintf("%+g RNG", u.Range))
+	}
+	return strings.Join(parts, " ")
+}
+
+// UpgradeOption is a purchasable next step for a placed tower.
+type UpgradeOption struct {
+	BranchID string // empty for linear levels
+	Level    int    // tower level after buying
+	Def      UpgradeDef
+	Cost     int
+}
+
+// UpgradeCost returns the price of reaching level (1-based). Explicit costs win; otherwise
+// the tower cost is scaled by CostScale per level.
+func (d *TowerDef) UpgradeCost(level int, u UpgradeDef) int {
+	if u.Cost > 0 {
+		return u.Cost
+	}
+	return int(math.Round(float64(d.Cost) * math.Pow(d.Upgrades.CostScale, float64(level))))
+}
+
+// Branch returns the branch with the given ID, or nil if not found.
+func (d *TowerDef) Branch(id string) *UpgradeBranch {
+	for i := range d.Upgrades.Branches {
+		if d.Upgrades.Branches[i].ID == id {
+			return &d.Upgrades.Branches[i]
+		}
+	}
+	return nil
+}
+
+// NextUpgrades returns the upgrades available to a tower at level (0 = unupgraded) on branch.
+// Linear levels come first; once they are exhausted and no branch is chosen, one option per branch is returned.
+func (d *TowerDef) NextUpgrades(level int, branch string) []UpgradeOption {
+	linear := len(d.Upgrades.Levels)
+	if level < linear {
+		u := d.Upgrades.Levels[level]
+		return []UpgradeOption{{Level: level + 1, Def: u, Cost: d.UpgradeCost(level+1, u)}}
+	}
+	if branch == "" {
+		var out []UpgradeOption
+		for _, b := range d.Upgrades.Branches {
+			u := b.Levels[0]
+			out = append(out, UpgradeOption{BranchID: b.ID, Level: level + 1, Def: u, Cost: d.UpgradeCost(level+1, u)})
+		}
+		return out
+	}
+	b := d.Branch(branch)
+	if b == nil {
+		return nil
+	}
+	tier := level - linear
+	if tier >= len(b.Levels) {
+		return nil
+	}
+	u := b.Levels[tier]
+	return []UpgradeOption{{BranchID: b.ID, Level: level + 1, Def: u, Cost: d.UpgradeCost(level+1, u)}}
+}
+
+// Rune returns the glyph used to draw the tower.
+func (d *TowerDef) Rune() rune {
+	r, _ := utf8.DecodeRuneInString(d.Symbol)
+	return r
+}
+
+// TowerDatabase holds all loaded tower definitions.
+type TowerDatabase struct {
+	Towers map[string]TowerDef // id -> definition
+	Order  []string            // ids in file order (build menu order)
+}
+
+// Get returns a tower definition by ID, or nil if not found.
+func (db *TowerDatabase) Get(id string) *TowerDef {
+	def, ok := db.Towers[id]
+	if !ok {
+		return nil
+	}
+	return &def
+}
+
+// At returns the definition at build menu index i (wraps around), or nil if empty.
+func (db *TowerDatabase) At(i int) *TowerDef {
+	if len(db.Order) == 0 {
+		return nil
+	}
+	i %= len(db.Order)
+	if i < 0 {
+		i += len(db.Order)
+	}
+	return db.Get(db.Order[i])
+}
@@ -4,15 +4,25 @@ import (
 	"embed"
 	"fmt"
 	"log"
+
+	"terminal-td/internal/content"
 )
 
 //go:embed data/*.json
 var wavesFS embed.FS
 
-// LoadWavesForMap loads waves for a specific map ID (e.g., "classic", "desert").
+// LoadWavesForMap loads waves for a specific map ID (e.g., "classic", "desert"). A <map id>.json
+// from the content directory or a mod takes precedence over the embedded file.
 func LoadWavesForMap(mapID string) ([]WaveDef, error) {
-	filename := fmt.Sprintf("data/%s.json", mapID)
-	data, err := wavesFS.ReadFile(filename)
+	name := mapID + ".json"
+	var data []byte
+	var err error
+	if f, ok := content.Find(content.WavesDir, name); ok {
+		data, err = f.Read()
+		log.Printf("DEBUG: using %s waves for map %q from %s", f.Source, mapID, f.Path)
+	} else {
+		data, err = wavesFS.ReadFile("data/" + name)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("waves for map %q: %w", mapID, err)
 	}
@@ -0,0 +1,143 @@
+package waves
+
+import (
+	"log"
+	"math"
+	"math/rand/v2"
+)
+
+// Generator tuning.
+const (
+	DefaultBaseBudget = 60.0 // budget of the first generated wave when there is no scripted wave to measure
+	BudgetGrowth      = 1.15 // budget multiplier per generated wave
+	BossEvery         = 5    // every BossEvery-th generated wave adds a boss group
+	maxGroupCount     = 40
+	groupStagger      = 2.0 // seconds between generated group start delays
+)
+
+// Candidate is an enemy type the generator may pick.
+type Candidate struct {
+	ID     string
+	HP     float64
+	Speed  float64
+	Reward int
+	Boss   bool // only used for boss groups
+}
+
+// Cost is the budget an enemy consumes: tougher, faster and more rewarding enemies cost more.
+func (c Candidate) Cost() float64 {
+	return EnemyCost(c.HP, c.Speed, c.Reward)
+}
+
+// EnemyCost returns the generator budget cost of one enemy.
+func EnemyCost(hp, speed float64, reward int) float64 {
+	return hp*(1+speed/5)/4 + float64(reward)/2
+}
+
+// Generator builds endless-mode waves from a seeded RNG and a growing budget.
+type Generator struct {
+	SpawnIDs   []string
+	Candidates []Candidate
+	BaseBudget float64
+
+	generated int
+	rng       *rand.Rand
+}
+
+// NewGenerator creates a generator drawing from rng. Candidates and spawn IDs should be in a
+// stable order so the same RNG state always yields the same waves.
+func NewGenerator(rng *rand.Rand, spawnIDs []string, candidates []Candidate, baseBudget float64) *Generator {
+	if baseBudget <= 0 {
+		baseBudget = DefaultBaseBudget
+	}
+	return &Generator{
+		SpawnIDs:   spawnIDs,
+		Candidates: candidates,
+		BaseBudget: baseBudget,
+		rng:        rng,
+	}
+}
+
+// WaveBudget returns the total cost of every enemy in the wave (used to seed BaseBudget).
+func WaveBudget(w WaveDef, cost func(enemyType string) float64) float64 {
+	total := 0.0
+	for _, g := range w.Groups {
+		total += cost(g.EnemyType) * float64(g.Count)
+	}
+	return total
+}
+
+// Next generates the next wave, numbered waveNum. Difficulty scaling is applied when the
+// wave starts, as for scripted waves.
+func (gen *Generator) Next(waveNum int) WaveDef {
+	gen.generated++
+	k := gen.generated
+	budget := gen.BaseBudget * math.Pow(BudgetGrowth, float64(k))
+
+	wave := WaveDef{Wave: waveNum}
+	if len(gen.SpawnIDs) == 0 {
+		return wave
+	}
+
+	var regular, bosses []Candidate
+	for _, c := range gen.Candidates {
+		if c.Boss {
+			bosses = append(bosses, c)
+		} else {
+			regular = append(regular, c)
+		}
+	}
+
+	if k%BossEvery == 0 && len(bosses) > 0 {
+		boss := bosses[gen.rng.IntN(len(bosses))]
+		budget -= boss.Cost()
+		wave.Groups = append(wave.Groups, SpawnGroupDef{
+			SpawnID:    gen.SpawnIDs[k%len(gen.SpawnIDs)],
+			EnemyType:  boss.ID,
+			Count:      1,
+			Interval:   1,
+			StartDelay: groupStagger * 3,
+			Boss:       true,
+			BossBonus:  int(boss.Cost()),
+		})
+	}
+
+	numGroups := min(1+k/3, 4)
+	share := math.Max(budget, 0) / float64(numGroups)
+	for i := range numGroups {
+		if len(regular) == 0 {
+			break
+		}
+		c := gen.pick(regular, share)
+		count := int(share / c.Cost())
+		count = max(1, min(count, maxGroupCount))
+		wave.Groups = append(wave.Groups, SpawnGroupDef{
+			SpawnID:    gen.SpawnIDs[(k+i)%len(gen.SpawnIDs)],
+			EnemyType:  c.ID,
+			Count:      count,
+			Interval:   0.6 + 0.6*gen.rng.Float64(),
+			StartDelay: groupStagger * float64(i),
+		})
+	}
+
+	log.Printf("generated wave %d (endless #%d) with %d groups, budget %.0f", waveNum, k, len(wave.Groups), budget)
+	return wave
+}
+
+// pick chooses a random candidate that fits in budget, falling back to the cheapest.
+func (gen *Generator) pick(candidates []Candidate, budget float64) Candidate {
+	var affordable []Candidate
+	cheapest := candidates[0]
+	for _, c := range candidates {
+		if c.Cost() <= budget {
+			affordable = append(affordable, c)
+		}
+		if c.Cost() < cheapest.Cost() {
+			cheapest = c
+		}
+	}
+	if len(affordable) == 0 {
+		return cheapest
+	}
+	return affordable[gen.rng.IntN(len(affordable))]
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"log"
+
+	"terminal-td/internal/content"
 )
 
 // LoadWaves reads wave definitions from r.
@@ -16,27 +18,10 @@ func LoadWaves(r io.Reader) ([]WaveDef, error) {
 	if err := json.NewDecoder(r).Decode(&defs); err != nil {
 		return nil, fmt.Errorf("wave decode: %w", err)
 	}
-	for i, wave := range defs.Waves {
-		if wave.Wave <= 0 {
-			return nil, fmt.Errorf("wave %d has invalid wave number %d", i, wave.Wave)
-		}
-		if len(wave.Groups) == 0 {
-			return nil, fmt.Errorf("wave %d has no spawn groups", wave.Wave)
-		}
-		for j, group := range wave.Groups {
-			if group.SpawnID == "" {
-				return nil, fmt.Errorf("wave %d group %d has empty spawn_id", wave.Wave, j)
-			}
-			if group.EnemyType == "" {
-				return nil, fmt.Errorf("wave %d group %d has empty enemy_type", wave.Wave, j)
-			}
-			if group.Count <= 0 {
-				return nil, fmt.Errorf("wave %d group %d has invalid count %d", wave.Wave, j, group.Count)
-			}
-			if group.Interval <= 0 {
-				return nil, fmt.Errorf("wave %d group %d has invalid interval %f", wave.Wave, j, group.Interval)
-			}
-		}
+	if problems := ValidateWaves(defs.Waves); len(problems) > 0 {
+		return nil, problems[0]
+	}
+	for _, wave := range defs.Waves {
 		log.Printf("loaded wave %d with %d groups", wave.Wave, len(wave.Groups))
 	}
 	return defs.Waves, nil
@@ -46,3 +31,55 @@ func LoadWaves(r io.Reader) ([]WaveDef, error) {
 func LoadWavesBytes(data []byte) ([]WaveDef, error) {
 	return LoadWaves(bytes.NewReader(data))
 }
+
+// ValidateWaves checks every wave of a waves file, with paths from the file's root ($.waves[i]).
+// Spawn IDs and enemy types are checked against the map and enemies separately.
+func ValidateWaves(waves []WaveDef) []content.Problem {
+	var problems []content.Problem
+	for i := range waves {
+		problems = append(problems, content.Under(fmt.Sprintf("$.waves[%d]", i), waves[i].Validate())...)
+	}
+	return problems
+}
+
+// Validate checks a wave and its groups, with paths relative to the wave.
+func (w *WaveDef) Validate() []content.Problem {
+	var problems []content.Problem
+	if w.Wave <= 0 {
+		problems = append(problems, content.Problemf(".wave", "invalid wave number %d", w.Wave))
+	}
+	if len(w.Groups) == 0 {
+		problems = append(problems, content.Problemf(".groups", "wave %d has no spawn groups", w.Wave))
+	}
+	for j := range w.Groups {
+		problems = append(problems, content.Under(fmt.Sprintf(".groups[%d]", j), w.Groups[j].Validate())...)
+	}
+	return problems
+}
+
+// Validate checks a spawn group, with paths relative to the group.
+func (g *SpawnGroupDef) Validate() []content.Problem {
+	var problems []content.Problem
+	add := func(path, format string, args ...any) {
+		problems = append(problems, content.Problemf(path, format, args...))
+	}
+	if g.SpawnID == "" {
+		add(".spawn_id", "empty spawn_id")
+	}
+	if g.EnemyType == "" {
+		add(".enemy_type", "empty enemy_type")
+	}
+	if g.Count <= 0 {
+		add(".count", "invalid count %d", g.Count)
+	}
+	if g.Interval <= 0 {
+		add(".interval", "invalid interval %g", g.Interval)
+	}
+	if g.BossBonus < 0 {
+		add(".boss_bonus", "invalid boss_bonus %d", g.BossBonus)
+	}
+	if g.BossBonus > 0 && !g.Boss {
+		add(".boss_bonus", "boss_bonus set without boss")
+	}
+	return problems
+}
@@ -13,73 +13,170 @@ type SpawnGroupDef struct {
 	Count      int     `json:"count"`
 	Interval   float64 `json:"interval"`
 	StartDelay float64 `json:"start_delay"`
+	Boss       bool    `json:"boss"`       // enemies from this group are bosses (boss HP bar, wave bonus)
+	BossBonus  int     `json:"boss_bonus"` // money and score for clearing the wave; 0 uses DefaultClearBonus
 }
 
+// DefaultClearBonus is the score awarded for clearing a wave without bosses.
+const DefaultClearBonus = 100
+
 // ActiveSpawnGroup tracks spawning progress for a group.
 type ActiveSpawnGroup struct {
 	Def        SpawnGroupDef
+	Count      int     // enemies to spawn after difficulty scaling
+	Interval   float64 // seconds between spawns after difficulty scaling
 	Spawned    int
 	Timer      float64
 	DelayTimer float64
 	Completed  bool
 }
 
-// WaveManager manages wave definitions and active spawning groups.
+// ActiveWave is a started wave that is still spawning or still has enemies alive.
+type ActiveWave struct {
+	Index        int // index into WaveManager.Waves
+	Groups       []ActiveSpawnGroup
+	EnemiesAlive int
+}
+
+// SpawningDone reports whether every group of the wave has finished spawning.
+func (aw *ActiveWave) SpawningDone() bool {
+	for _, group := range aw.Groups {
+		if !group.Completed {
+			return false
+		}
+	}
+	return true
+}
+
+// WaveManager manages wave definitions and the waves currently in play. Several waves can be
+// active at once when the player calls the next wave early.
 type WaveManager struct {
 	Waves        []WaveDef
-	CurrentWave  int
-	ActiveGroups []ActiveSpawnGroup
-	EnemiesAlive int
+	Next         int          // index of the next wave to start
+	Active       []ActiveWave // started waves still in play, oldest first
+	EnemiesAlive int          // across all active waves
+	Scripted     int          // number of waves loaded from JSON; later waves were generated
 }
 
 // NewWaveManager creates a wave manager from wave definitions.
 func NewWaveManager(waves []WaveDef) *WaveManager {
 	return &WaveManager{
-		Waves:        waves,
-		CurrentWave:  0,
-		ActiveGroups: nil,
-		EnemiesAlive: 0,
+		Waves:    waves,
+		Scripted: len(waves),
 	}
 }
 
-// StartWave initializes spawning groups for the current wave.
-func (wm *WaveManager) StartWave() {
-	if wm.CurrentWave < 0 || wm.CurrentWave >= len(wm.Waves) {
-		return
+// HasNext reports whether there is a wave left to start.
+func (wm *WaveManager) HasNext() bool {
+	return wm.Next >= 0 && wm.Next < len(wm.Waves)
+}
+
+// StartNext starts the next wave and returns it, or nil if there is none.
+func (wm *WaveManager) StartNext() *ActiveWave {
+	if !wm.HasNext() {
+		return nil
 	}
-	wave := wm.Waves[wm.CurrentWave]
-	wm.ActiveGroups = make([]ActiveSpawnGroup, len(wave.Groups))
+	wave := wm.Waves[wm.Next]
+	aw := ActiveWave{Index: wm.Next, Groups: make([]ActiveSpawnGroup, len(wave.Groups))}
 	for i, group := range wave.Groups {
-		wm.ActiveGroups[i] = ActiveSpawnGroup{
+		aw.Groups[i] = ActiveSpawnGroup{
 			Def:        group,
+			Count:      group.Count,
+			Interval:   group.Interval,
 			Spawned:    0,
 			Timer:      0,
 			DelayTimer: group.StartDelay,
 			Completed:  false,
 		}
 	}
+	wm.Next++
+	wm.Active = append(wm.Active, aw)
+	return &wm.Active[len(wm.Active)-1]
 }
 
-// IsWaveComplete returns true if all groups are done and no enemies are alive.
-func (wm *WaveManager) IsWaveComplete() bool {
-	if len(wm.ActiveGroups) == 0 {
-		return false
+// Find returns the active wave with the given index, or nil.
+func (wm *WaveManager) Find(index int) *ActiveWave {
+	for i := range wm.Active {
+		if wm.Active[i].Index == index {
+			return &wm.Active[i]
+		}
 	}
-	for _, group := range wm.ActiveGroups {
-		if !group.Completed {
-			return false
+	return nil
+}
+
+// EnemyAdded counts a new enemy toward the wave with the given index.
+func (wm *WaveManager) EnemyAdded(index int) {
+	wm.EnemiesAlive++
+	if aw := wm.Find(index); aw != nil {
+		aw.EnemiesAlive++
+	}
+}
+
+// EnemyRemoved uncounts a dead or leaked enemy from the wave with the given index.
+func (wm *WaveManager) EnemyRemoved(index int) {
+	wm.EnemiesAlive--
+	if aw := wm.Find(index); aw != nil {
+		aw.EnemiesAlive--
+	}
+}
+
+// PopCleared removes and returns the active waves that are done spawning and have no enemies left.
+func (wm *WaveManager) PopCleared() []ActiveWave {
+	var cleared []ActiveWave
+	remaining := wm.Active[:0]
+	for _, aw := range wm.Active {
+		if aw.SpawningDone() && aw.EnemiesAlive <= 0 {
+			cleared = append(cleared, aw)
+		} else {
+			remaining = append(remaining, aw)
 		}
 	}
-	return wm.EnemiesAlive == 0
+	wm.Active = remaining
+	return cleared
 }
 
-// NextWave advances to the next wave and clears active groups.
-func (wm *WaveManager) NextWave() bool {
-	wm.CurrentWave++
-	wm.ActiveGroups = nil
-	wm.EnemiesAlive = 0
-	if wm.CurrentWave >= len(wm.Waves) {
+// IsBossWave reports whether wave index has a boss group.
+func (wm *WaveManager) IsBossWave(index int) bool {
+	if index < 0 || index >= len(wm.Waves) {
 		return false
 	}
-	return true
+	for _, g := range wm.Waves[index].Groups {
+		if g.Boss {
+			return true
+		}
+	}
+	return false
+}
+
+// ClearBonus returns the bonus for clearing wave index: the sum of its boss groups'
+// bonuses, or DefaultClearBonus for a wave without bosses.
+func (wm *WaveManager) ClearBonus(index int) int {
+	if !wm.IsBossWave(index) {
+		return DefaultClearBonus
+	}
+	bonus := 0
+	for _, g := range wm.Waves[index].Groups {
+		if !g.Boss {
+			continue
+		}
+		if g.BossBonus > 0 {
+			bonus += g.BossBonus
+		} else {
+			bonus += DefaultClearBonus
+		}
+	}
+	return bonus
+}
+
+// AppendWave adds a generated wave after the existing ones.
+func (wm *WaveManager) AppendWave(w WaveDef) {
+	wm.Waves = append(wm.Waves, w)
+}
+
+// Reset returns to before the first wave and drops every generated wave.
+func (wm *WaveManager) Reset() {
+	wm.Waves = wm.Waves[:wm.Scripted]
+	wm.Next = 0
+	wm.Active = nil
+	wm.EnemiesAlive = 0
 }